@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchResult is the outcome of one request run by BatchDo
+type BatchResult struct {
+	// StatusCode is the response status, or 0 if no response was received
+	StatusCode int
+
+	// Body holds the raw response body, or the error body of a non-2xx response
+	Body []byte
+
+	// Err is the error of the request, as returned by RequestBuilder.DoBytes
+	Err error
+}
+
+// BatchDo runs the builders with at most concurrency requests in flight and
+// returns their results in input order. Each builder is executed with ctx. Once
+// ctx is canceled no further requests are started; those left out get ctx.Err()
+// as their error, which is also returned. Failures of individual requests are
+// only reported in their results. A concurrency below 1 runs one at a time.
+//
+// Example usage:
+//
+//	builders := make([]*httpclient.RequestBuilder, len(ids))
+//	for i, id := range ids {
+//	    builders[i] = client.GET("/api/v1/users/{id}").WithPathParam("id", id)
+//	}
+//	results, err := httpclient.BatchDo(ctx, builders, 4)
+func BatchDo(ctx context.Context, builders []*RequestBuilder, concurrency int) ([]BatchResult, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BatchResult, len(builders))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	next := 0
+schedule:
+	for ; next < len(builders); next++ {
+		select {
+		case <-ctx.Done():
+			break schedule
+		case sem <- struct{}{}:
+		}
+		// A slot may free up together with cancellation, so check again
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			body, status, err := builders[i].WithContext(ctx).DoBytes()
+			results[i] = BatchResult{StatusCode: status, Body: body, Err: err}
+		}(next)
+	}
+	wg.Wait()
+
+	if next < len(builders) {
+		for i := next; i < len(builders); i++ {
+			results[i].Err = ctx.Err()
+		}
+		return results, ctx.Err()
+	}
+	return results, nil
+}
@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchDo(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(r.URL.Query().Get("id")))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	builders := make([]*RequestBuilder, 20)
+	for i := range builders {
+		builders[i] = client.GET("/items").WithQuery("id", strconv.Itoa(i))
+	}
+
+	results, err := BatchDo(context.Background(), builders, 4)
+	if err != nil {
+		t.Fatalf("BatchDo failed: %v", err)
+	}
+
+	if len(results) != 20 {
+		t.Fatalf("Expected 20 results, got %d", len(results))
+	}
+	for i, res := range results {
+		if res.Err != nil {
+			t.Errorf("Request %d failed: %v", i, res.Err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("Request %d: expected status 200, got %d", i, res.StatusCode)
+		}
+		if string(res.Body) != strconv.Itoa(i) {
+			t.Errorf("Request %d: expected body '%d', got '%s'", i, i, res.Body)
+		}
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 4 {
+		t.Errorf("Expected at most 4 concurrent requests, got %d", got)
+	}
+}
+
+func TestBatchDo_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		cancel()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	builders := make([]*RequestBuilder, 5)
+	for i := range builders {
+		builders[i] = client.GET("/items")
+	}
+
+	results, err := BatchDo(ctx, builders, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("Expected 1 request to be sent, got %d", got)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(results))
+	}
+	if !errors.Is(results[4].Err, context.Canceled) {
+		t.Errorf("Expected unscheduled request to fail with context.Canceled, got %v", results[4].Err)
+	}
+}
@@ -0,0 +1,141 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default circuit breaker settings
+var (
+	DefaultCircuitBreakerMaxFailures = 5
+	DefaultCircuitBreakerCooldown    = 30 * time.Second
+)
+
+// CircuitBreakerSettings configures WithCircuitBreaker
+type CircuitBreakerSettings struct {
+	// MaxFailures is the number of consecutive failures that trips the breaker
+	// (default: DefaultCircuitBreakerMaxFailures)
+	MaxFailures int
+	// Cooldown is how long the breaker stays open before letting a probe request
+	// through (default: DefaultCircuitBreakerCooldown)
+	Cooldown time.Duration
+	// IsFailure optionally decides whether an attempt counts as a failure. By
+	// default transport errors and 5xx responses do, but not cancellations.
+	IsFailure func(*http.Response, error) bool
+}
+
+// WithCircuitBreaker fails requests fast with ErrCircuitOpen once a downstream
+// keeps failing. After MaxFailures consecutive failed attempts the breaker opens
+// for Cooldown, then half-opens to let a single probe through: a success closes
+// it, a failure opens it again. Every attempt counts, including retries, and an
+// open breaker stops retrying immediately. The breaker is shared by sub-clients
+// created with WithBasePath.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(c *HTTPClient) {
+		if settings.MaxFailures <= 0 {
+			settings.MaxFailures = DefaultCircuitBreakerMaxFailures
+		}
+		if settings.Cooldown <= 0 {
+			settings.Cooldown = DefaultCircuitBreakerCooldown
+		}
+		if settings.IsFailure == nil {
+			settings.IsFailure = defaultIsFailure
+		}
+		c.circuitBreaker = &circuitBreaker{settings: settings, now: time.Now}
+	}
+}
+
+// defaultIsFailure counts transport errors, except cancellations, and 5xx responses as failures
+func defaultIsFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp == nil || resp.StatusCode >= 500
+}
+
+// circuit breaker states
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks consecutive failures and the state of the circuit
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether an attempt may be sent, moving an open breaker whose
+// cooldown has elapsed to half-open and admitting a single probe
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.settings.Cooldown {
+			return false
+		}
+		cb.state = circuitHalfOpen
+		cb.probing = true
+		return true
+	case circuitHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of an attempt
+func (cb *circuitBreaker) record(resp *http.Response, err error) {
+	failed := cb.settings.IsFailure(resp, err)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probing = false
+	if !failed {
+		cb.state = circuitClosed
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.state == circuitHalfOpen || cb.failures >= cb.settings.MaxFailures {
+		cb.state = circuitOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// wrap returns a Doer that sends requests through next while the breaker allows it
+func (cb *circuitBreaker) wrap(next Doer) Doer {
+	return doerFunc(func(req *http.Request) (*http.Response, error) {
+		if !cb.allow() {
+			return nil, ErrCircuitOpen
+		}
+		resp, err := next.Do(req)
+		cb.record(resp, err)
+		return resp, err
+	})
+}
+
+// doerFunc adapts a function to the Doer interface
+type doerFunc func(*http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
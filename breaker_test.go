@@ -0,0 +1,123 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for circuit breaker tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestCircuitBreaker(t *testing.T) {
+	var hits, healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if atomic.LoadInt32(&healthy) == 1 {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithCircuitBreaker(CircuitBreakerSettings{MaxFailures: 3, Cooldown: time.Minute}))
+	clock := &fakeClock{now: time.Now()}
+	client.(*HTTPClient).circuitBreaker.now = clock.Now
+
+	// Trips after three consecutive failures
+	for i := 0; i < 3; i++ {
+		var apiErr *APIError
+		if err := client.GET("/").Do(nil); !errors.As(err, &apiErr) {
+			t.Fatalf("Expected APIError on attempt %d, got %v", i+1, err)
+		}
+	}
+	if err := client.GET("/").Do(nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen once tripped, got %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("Expected 3 requests to reach the server, got %d", got)
+	}
+
+	// Stays open during the cooldown
+	clock.now = clock.now.Add(30 * time.Second)
+	if err := client.GET("/").Do(nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen during cooldown, got %v", err)
+	}
+
+	// A failed probe after the cooldown opens it again
+	clock.now = clock.now.Add(time.Minute)
+	var apiErr *APIError
+	if err := client.GET("/").Do(nil); !errors.As(err, &apiErr) {
+		t.Fatalf("Expected probe to reach the server, got %v", err)
+	}
+	if err := client.GET("/").Do(nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after failed probe, got %v", err)
+	}
+
+	// A successful probe closes it
+	atomic.StoreInt32(&healthy, 1)
+	clock.now = clock.now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		if err := client.GET("/").Do(nil); err != nil {
+			t.Fatalf("Expected recovery, got %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&hits); got != 7 {
+		t.Errorf("Expected 7 requests to reach the server, got %d", got)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAdmitsOneProbe(t *testing.T) {
+	cb := &circuitBreaker{
+		settings: CircuitBreakerSettings{MaxFailures: 1, Cooldown: time.Minute, IsFailure: defaultIsFailure},
+	}
+	clock := &fakeClock{now: time.Now()}
+	cb.now = clock.Now
+
+	cb.record(nil, errors.New("connection refused"))
+	if cb.allow() {
+		t.Fatal("Expected breaker to be open")
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if !cb.allow() {
+		t.Fatal("Expected a probe after the cooldown")
+	}
+	if cb.allow() {
+		t.Error("Expected a single probe while half-open")
+	}
+}
+
+func TestCircuitBreaker_StopsRetries(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetry(5, time.Millisecond, 5*time.Millisecond),
+		WithCircuitBreaker(CircuitBreakerSettings{MaxFailures: 2}))
+
+	err := client.GET("/").Do(nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("Expected retries to stop after 2 attempts, got %d", got)
+	}
+}
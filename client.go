@@ -5,7 +5,11 @@ package httpclient
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -20,7 +24,8 @@ type Client interface {
 	// NewRequest creates a new request builder
 	NewRequest() *RequestBuilder
 
-	// GET creates a GET request builder
+	// GET creates a GET request builder.
+	// path is resolved against BaseURL unless it is an absolute URL, which is used verbatim.
 	GET(path string) *RequestBuilder
 
 	// POST creates a POST request builder
@@ -34,6 +39,15 @@ type Client interface {
 
 	// PATCH creates a PATCH request builder
 	PATCH(path string) *RequestBuilder
+
+	// HEAD creates a HEAD request builder
+	HEAD(path string) *RequestBuilder
+
+	// OPTIONS creates an OPTIONS request builder
+	OPTIONS(path string) *RequestBuilder
+
+	// WithBasePath returns a client for the sub-path of BaseURL, sharing this client's configuration
+	WithBasePath(path string) Client
 }
 
 // HTTPClient implements the Client interface
@@ -44,9 +58,85 @@ type HTTPClient struct {
 
 	// Retry configuration
 	retryConfig *RetryConfig
+	retryRand   *lockedRand
 
 	// Response middleware
 	responseMiddleware []ResponseMiddleware
+
+	// JSON error envelope field names (nil uses the defaults)
+	errorMessageFields []string
+	errorCodeFields    []string
+
+	// Decodes error responses before the built-in parsing
+	errorDecoder func(*http.Response) error
+
+	// Send PUT/PATCH/DELETE as POST with X-HTTP-Method-Override
+	methodOverride bool
+
+	// Validates 2xx responses before decoding
+	successValidator SuccessValidator
+
+	// Validates decoded results
+	resultValidator ResultValidator
+
+	// Root context for new requests (nil means context.Background)
+	baseCtx context.Context
+
+	// Deadline for a whole call, including retries and backoff
+	totalTimeout time.Duration
+
+	// Deadline for calls whose context has none
+	defaultRequestTimeout time.Duration
+
+	// Maximum request body size in bytes (0 means unlimited)
+	maxRequestBytes int
+
+	// Content encodings advertised and decoded by the client
+	autoDecompress []string
+
+	// Return 3xx responses instead of following them
+	noRedirects bool
+
+	// Codecs keyed by media type (JSON is always available)
+	codecs map[string]Codec
+
+	// gzip level for request bodies (nil means uncompressed)
+	compressionLevel *int
+
+	// Receives per-call metrics
+	metrics MetricsRecorder
+
+	// Cap on error body reads (0 means DefaultMaxErrorBodyBytes, negative means unlimited)
+	maxErrorBodyBytes int
+
+	// Callbacks around the round trip, including retries
+	onRequestStart  []func(*http.Request)
+	onRequestFinish []func(*http.Request, *http.Response, error, time.Duration)
+
+	// Debug output buffered per call and written only on failure
+	debugOnError *DebugOptions
+
+	// Headers seeded on every request builder
+	defaultHeaders http.Header
+
+	// Query parameters added to every request that does not set them
+	defaultQuery map[string]string
+
+	// Invalid configuration from an option, reported by every request
+	configErr error
+
+	// Transport of the default http.Client, nil if it was replaced by WithHTTPClient
+	transport *http.Transport
+
+	// Dialer of the default transport
+	dialer *net.Dialer
+
+	// Fails requests fast while a downstream keeps failing
+	circuitBreaker *circuitBreaker
+
+	// TLS settings applied to the transport once all options have run
+	tlsBase    *tls.Config
+	tlsOptions []func(*tls.Config)
 }
 
 // Config holds the HTTP client configuration
@@ -58,6 +148,16 @@ type Config struct {
 	MaxIdleConns        int
 	MaxIdleConnsPerHost int
 	IdleConnTimeout     time.Duration
+
+	// Connection establishment timeouts (optional), bounding the dial and
+	// the TLS handshake separately from Timeout. Zero means no limit.
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+
+	// ResponseHeaderTimeout bounds the wait for response headers once the
+	// request has been sent, failing fast on stalled servers even when Timeout
+	// is generous for large downloads. Zero means no limit (optional).
+	ResponseHeaderTimeout time.Duration
 }
 
 // Option is a functional option for configuring HTTPClient
@@ -71,11 +171,18 @@ func NewClient(config *Config, opts ...Option) Client {
 		}
 	}
 
-	// Create http.Client with connection pooling
+	// Create http.Client with connection pooling. HTTP/2 is attempted explicitly,
+	// as the custom dialer would otherwise disable it, like http.DefaultTransport.
+	dialer := &net.Dialer{Timeout: config.DialTimeout}
 	transport := &http.Transport{
-		MaxIdleConns:        config.MaxIdleConns,
-		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
-		IdleConnTimeout:     config.IdleConnTimeout,
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
 	}
 
 	// Apply defaults
@@ -92,6 +199,8 @@ func NewClient(config *Config, opts ...Option) Client {
 			Timeout:   config.Timeout,
 			Transport: transport,
 		},
+		transport: transport,
+		dialer:    dialer,
 	}
 
 	// Apply options
@@ -99,6 +208,19 @@ func NewClient(config *Config, opts ...Option) Client {
 		opt(client)
 	}
 
+	client.applyTLS()
+
+	// Stop following redirects, without mutating a caller-supplied http.Client
+	if client.noRedirects {
+		if hc, ok := client.httpClient.(*http.Client); ok {
+			noRedirect := *hc
+			noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			}
+			client.httpClient = &noRedirect
+		}
+	}
+
 	return client
 }
 
@@ -113,7 +235,174 @@ func WithRetry(maxAttempts int, waitTime, maxWaitTime time.Duration) Option {
 	}
 }
 
-// WithMiddleware adds request middleware
+// WithRetryConfig configures retry behavior from a full RetryConfig, including
+// the optional ShouldRetry and OnRetry functions. Zero values use the defaults.
+func WithRetryConfig(config RetryConfig) Option {
+	return func(c *HTTPClient) {
+		applyRetryDefaults(&config)
+		c.retryConfig = &config
+	}
+}
+
+// WithRetryJitterSeed seeds a per-client random source for retry backoff jitter,
+// making backoff sequences reproducible without touching the global math/rand state
+func WithRetryJitterSeed(seed int64) Option {
+	return func(c *HTTPClient) {
+		c.retryRand = newLockedRand(seed)
+	}
+}
+
+// WithTotalTimeout bounds the total time of a call, including all retry attempts
+// and backoff waits. Config.Timeout, by contrast, applies to each attempt.
+func WithTotalTimeout(d time.Duration) Option {
+	return func(c *HTTPClient) {
+		c.totalTimeout = d
+	}
+}
+
+// WithDefaultRequestTimeout bounds calls whose context carries no deadline,
+// guarding against callers that forget to set one. Calls with a deadline
+// are left unchanged.
+func WithDefaultRequestTimeout(d time.Duration) Option {
+	return func(c *HTTPClient) {
+		c.defaultRequestTimeout = d
+	}
+}
+
+// WithMaxRequestBytes rejects request bodies larger than n bytes with ErrRequestTooLarge.
+// Byte bodies are checked before sending; streamed bodies fail once the limit is exceeded.
+func WithMaxRequestBytes(n int) Option {
+	return func(c *HTTPClient) {
+		c.maxRequestBytes = n
+	}
+}
+
+// WithMaxErrorBodyBytes caps how much of a non-2xx response body is read into
+// APIError.Body, guarding against huge error pages. Longer bodies are truncated.
+// The default is DefaultMaxErrorBodyBytes; a negative n means unlimited.
+func WithMaxErrorBodyBytes(n int) Option {
+	return func(c *HTTPClient) {
+		c.maxErrorBodyBytes = n
+	}
+}
+
+// WithAutoDecompress advertises the given content encodings in Accept-Encoding
+// and transparently decodes responses that use them. Without arguments it
+// enables gzip and deflate. gzip and deflate responses are decoded with or
+// without this option, so it mainly controls what is advertised. Other
+// encodings, such as br or zstd, need a decoder registered with
+// RegisterContentDecoder. An Accept-Encoding header set on the request wins.
+func WithAutoDecompress(encodings ...string) Option {
+	return func(c *HTTPClient) {
+		if len(encodings) == 0 {
+			encodings = []string{"gzip", "deflate"}
+		}
+		c.autoDecompress = encodings
+	}
+}
+
+// WithProxy sends requests through the proxy at proxyURL, such as
+// "http://proxy.corp:3128", instead of the one from the HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables used by default. An invalid URL fails
+// every request. This has no effect after WithHTTPClient.
+func WithProxy(proxyURL string) Option {
+	return func(c *HTTPClient) {
+		if c.transport == nil {
+			return
+		}
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			c.configErr = fmt.Errorf("invalid proxy URL %q", proxyURL)
+			return
+		}
+		c.transport.Proxy = http.ProxyURL(u)
+	}
+}
+
+// WithUnixSocket sends all traffic to the unix domain socket at path. The host
+// in BaseURL is still used for the Host header but is not dialed. Config.DialTimeout
+// still applies. This only affects the default transport and has no effect after
+// WithHTTPClient.
+func WithUnixSocket(path string) Option {
+	return func(c *HTTPClient) {
+		if c.transport == nil {
+			return
+		}
+		c.transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return c.dialer.DialContext(ctx, "unix", path)
+		}
+	}
+}
+
+// WithHTTP2 forces or disables HTTP/2 on the default transport. When enabled,
+// only HTTP/2 is used: negotiated over TLS for https URLs, and as h2c with prior
+// knowledge for http URLs, so the server must support it. When disabled, only
+// HTTP/1.1 is used, e.g. to work around an intermediary with a broken HTTP/2
+// implementation. Without this option HTTP/2 is negotiated over TLS when the
+// server supports it. This has no effect with WithHTTPClient.
+func WithHTTP2(enabled bool) Option {
+	return func(c *HTTPClient) {
+		if c.transport == nil {
+			return
+		}
+		var protocols http.Protocols
+		if enabled {
+			protocols.SetHTTP2(true)
+			protocols.SetUnencryptedHTTP2(true)
+		} else {
+			protocols.SetHTTP1(true)
+		}
+		c.transport.Protocols = &protocols
+	}
+}
+
+// WithNoRedirects stops the client from following redirects. A 3xx response is
+// then returned as a success rather than an APIError, and its Location header is
+// available as Response.Location from DoResponse. This only takes effect when the
+// underlying Doer is an *http.Client.
+func WithNoRedirects() Option {
+	return func(c *HTTPClient) {
+		c.noRedirects = true
+	}
+}
+
+// WithCompression gzip-compresses every request body at the given level, as if
+// RequestBuilder.WithGzip were called on each request. It is a no-op for requests
+// without a body. An invalid level makes requests fail when executed.
+func WithCompression(level int) Option {
+	return func(c *HTTPClient) {
+		c.compressionLevel = &level
+	}
+}
+
+// WithDefaultHeaders sets headers on every request created by the client.
+// Unlike HeaderMiddleware, a request can override them with WithHeader.
+func WithDefaultHeaders(headers map[string]string) Option {
+	return func(c *HTTPClient) {
+		if c.defaultHeaders == nil {
+			c.defaultHeaders = make(http.Header, len(headers))
+		}
+		for k, v := range headers {
+			c.defaultHeaders.Set(k, v)
+		}
+	}
+}
+
+// WithDefaultQuery adds query parameters to every request, such as an API
+// version or key. A request that sets the same key with WithQuery replaces the
+// default, keeping all of its own values for that key.
+func WithDefaultQuery(params map[string]string) Option {
+	return func(c *HTTPClient) {
+		if c.defaultQuery == nil {
+			c.defaultQuery = make(map[string]string, len(params))
+		}
+		for k, v := range params {
+			c.defaultQuery[k] = v
+		}
+	}
+}
+
+// WithMiddleware adds request middleware. Middleware runs in the order it was added.
 func WithMiddleware(mw Middleware) Option {
 	return func(c *HTTPClient) {
 		c.middleware = append(c.middleware, mw)
@@ -121,27 +410,143 @@ func WithMiddleware(mw Middleware) Option {
 }
 
 // WithResponseMiddleware adds response middleware
-// Response middleware is called after receiving HTTP response but before decoding
+// Response middleware is called after receiving HTTP response but before decoding,
+// in the order it was added. The first error stops the chain.
 func WithResponseMiddleware(mw ResponseMiddleware) Option {
 	return func(c *HTTPClient) {
 		c.responseMiddleware = append(c.responseMiddleware, mw)
 	}
 }
 
+// WithOnRequestStart registers a callback invoked just before a request is sent.
+// It runs once per call, after request middleware, not once per retry attempt.
+func WithOnRequestStart(fn func(*http.Request)) Option {
+	return func(c *HTTPClient) {
+		c.onRequestStart = append(c.onRequestStart, fn)
+	}
+}
+
+// WithOnRequestFinish registers a callback invoked once a call completes, with
+// the final response or error and the time spent across all retry attempts.
+// The response body has not been read yet and must not be consumed by fn.
+func WithOnRequestFinish(fn func(*http.Request, *http.Response, error, time.Duration)) Option {
+	return func(c *HTTPClient) {
+		c.onRequestFinish = append(c.onRequestFinish, fn)
+	}
+}
+
 // WithHTTPClient sets a custom http.Client (useful for testing)
 func WithHTTPClient(httpClient Doer) Option {
 	return func(c *HTTPClient) {
 		c.httpClient = httpClient
+		c.transport = nil
+	}
+}
+
+// WithErrorFields sets the JSON field names used to extract the message and
+// code from error response bodies. Fields are tried in order and the first
+// non-empty value wins. A nil slice keeps the default lookup for that value.
+func WithErrorFields(messageFields, codeFields []string) Option {
+	return func(c *HTTPClient) {
+		c.errorMessageFields = messageFields
+		c.errorCodeFields = codeFields
+	}
+}
+
+// WithErrorDecoder sets a function that turns non-2xx responses into errors,
+// for APIs whose error envelope the built-in parsing does not understand. When
+// it returns nil, the built-in parsing is used. The response body is capped as
+// set by WithMaxErrorBodyBytes, and a returned *APIError gets the request ID
+// and retry attempts filled in.
+//
+// Example usage:
+//
+//	httpclient.WithErrorDecoder(func(resp *http.Response) error {
+//	    var envelope struct{ Errors []struct{ Detail string } }
+//	    if json.NewDecoder(resp.Body).Decode(&envelope) != nil || len(envelope.Errors) == 0 {
+//	        return nil
+//	    }
+//	    return &httpclient.APIError{StatusCode: resp.StatusCode, Message: envelope.Errors[0].Detail}
+//	})
+func WithErrorDecoder(decode func(*http.Response) error) Option {
+	return func(c *HTTPClient) {
+		c.errorDecoder = decode
+	}
+}
+
+// WithMethodOverride sends PUT, PATCH and DELETE requests as POST with the
+// X-HTTP-Method-Override header set to the real method. This works around
+// proxies that block those methods.
+func WithMethodOverride() Option {
+	return func(c *HTTPClient) {
+		c.methodOverride = true
+	}
+}
+
+// WithSuccessValidator sets a validator run on every 2xx response before decoding.
+// It is meant for APIs that report errors in the body of a 200 response; the body
+// is buffered so the validator can read it. A returned error is returned from Do.
+func WithSuccessValidator(validate SuccessValidator) Option {
+	return func(c *HTTPClient) {
+		c.successValidator = validate
+	}
+}
+
+// WithResultValidator sets a validator run after a response is decoded into a
+// non-nil result. A returned error is returned from Do.
+func WithResultValidator(validate ResultValidator) Option {
+	return func(c *HTTPClient) {
+		c.resultValidator = validate
+	}
+}
+
+// WithBaseContext sets the context new requests derive from, such as a
+// service's shutdown context. RequestBuilder.WithContext still overrides it.
+func WithBaseContext(ctx context.Context) Option {
+	return func(c *HTTPClient) {
+		c.baseCtx = ctx
 	}
 }
 
 // NewRequest creates a new request builder
 func (c *HTTPClient) NewRequest() *RequestBuilder {
-	return &RequestBuilder{
+	ctx := c.baseCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	b := &RequestBuilder{
 		client:  c,
-		headers: make(map[string]string),
-		ctx:     context.Background(),
+		headers: c.defaultHeaders.Clone(),
+		ctx:     ctx,
+		err:     c.configErr,
+	}
+	if b.headers == nil {
+		b.headers = make(http.Header)
 	}
+	if c.compressionLevel != nil {
+		b.WithGzip(*c.compressionLevel)
+	}
+	return b
+}
+
+// WithBasePath returns a client whose base URL is this client's extended with
+// path, e.g. "/v1/users". It shares the transport, middleware, retry and all
+// other configuration; the original client is unaffected.
+//
+// Example usage:
+//
+//	users := client.WithBasePath("/v1/users")
+//	err := users.GET("/42").Do(&user) // GET {BaseURL}/v1/users/42
+func (c *HTTPClient) WithBasePath(path string) Client {
+	sub := *c
+	u, err := joinURL(c.baseURL, path)
+	if err != nil {
+		sub.configErr = err
+		return &sub
+	}
+	sub.baseURL = u.String()
+	return &sub
 }
 
 // GET creates a GET request builder
@@ -168,3 +573,13 @@ func (c *HTTPClient) DELETE(path string) *RequestBuilder {
 func (c *HTTPClient) PATCH(path string) *RequestBuilder {
 	return c.NewRequest().PATCH(path)
 }
+
+// HEAD creates a HEAD request builder
+func (c *HTTPClient) HEAD(path string) *RequestBuilder {
+	return c.NewRequest().HEAD(path)
+}
+
+// OPTIONS creates an OPTIONS request builder
+func (c *HTTPClient) OPTIONS(path string) *RequestBuilder {
+	return c.NewRequest().OPTIONS(path)
+}
@@ -1,11 +1,22 @@
 package httpclient
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -31,6 +42,88 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewClient_ConnectTimeouts(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL:             "https://api.example.com",
+		Timeout:             30 * time.Second,
+		DialTimeout:         2 * time.Second,
+		TLSHandshakeTimeout: 3 * time.Second,
+	}).(*HTTPClient)
+
+	if client.dialer.Timeout != 2*time.Second {
+		t.Errorf("Expected dial timeout 2s, got %v", client.dialer.Timeout)
+	}
+	if client.transport.TLSHandshakeTimeout != 3*time.Second {
+		t.Errorf("Expected TLS handshake timeout 3s, got %v", client.transport.TLSHandshakeTimeout)
+	}
+}
+
+func TestClient_TLSHandshakeTimeout(t *testing.T) {
+	// A server that accepts connections but never completes a handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close() }()
+		}
+	}()
+
+	client := NewClient(&Config{
+		BaseURL:             "https://" + listener.Addr().String(),
+		Timeout:             5 * time.Second,
+		TLSHandshakeTimeout: 50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	err = client.GET("/").Do(nil)
+	if err == nil || !strings.Contains(err.Error(), "TLS handshake timeout") {
+		t.Fatalf("Expected TLS handshake timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected handshake to time out quickly, took %v", elapsed)
+	}
+}
+
+func TestClient_ResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL:               server.URL,
+		Timeout:               5 * time.Second,
+		ResponseHeaderTimeout: 50 * time.Millisecond,
+	})
+
+	if got := client.(*HTTPClient).transport.ResponseHeaderTimeout; got != 50*time.Millisecond {
+		t.Errorf("Expected response header timeout 50ms, got %v", got)
+	}
+
+	start := time.Now()
+	err := client.GET("/slow").Do(nil)
+	if err == nil || !strings.Contains(err.Error(), "timeout awaiting response headers") {
+		t.Fatalf("Expected response header timeout, got %v", err)
+	}
+	if !IsTimeout(err) {
+		t.Errorf("Expected IsTimeout to report the error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected request to fail before the overall timeout, took %v", elapsed)
+	}
+}
+
 func TestClient_GET(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -62,6 +155,50 @@ func TestClient_GET(t *testing.T) {
 	}
 }
 
+func TestClient_HEAD_OPTIONS(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var result map[string]string
+	resp, err := client.HEAD("/api/v1/test").DoResponse(&result)
+	if err != nil {
+		t.Fatalf("HEAD request failed: %v", err)
+	}
+	if result != nil {
+		t.Errorf("Expected result to be left untouched, got %v", result)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got '%s'", got)
+	}
+
+	resp, err = client.OPTIONS("/api/v1/test").DoResponse(nil)
+	if err != nil {
+		t.Fatalf("OPTIONS request failed: %v", err)
+	}
+	if got := resp.Header.Get("Allow"); got != "GET, HEAD, OPTIONS" {
+		t.Errorf("Expected Allow header, got '%s'", got)
+	}
+
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodOptions {
+		t.Errorf("Expected methods [HEAD OPTIONS], got %v", methods)
+	}
+}
+
 func TestClient_POST_WithJSON(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -244,3 +381,1031 @@ func TestClient_WithMiddleware(t *testing.T) {
 		t.Fatalf("Request failed: %v", err)
 	}
 }
+
+func TestClient_MiddlewareSkipRequest(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cache := func(req *http.Request) error {
+		switch req.URL.Path {
+		case "/cached":
+			return SkipRequest(&http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"name":"cached"}`)),
+			})
+		case "/missing":
+			return SkipRequest(&http.Response{StatusCode: http.StatusNotFound})
+		case "/skipped":
+			return ErrSkipRequest
+		}
+		return nil
+	}
+
+	var seen []int
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(cache), WithResponseMiddleware(func(resp *http.Response) error {
+		seen = append(seen, resp.StatusCode)
+		return nil
+	}))
+
+	var result map[string]string
+	if err := client.GET("/cached").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["name"] != "cached" {
+		t.Errorf("Expected name 'cached', got '%s'", result["name"])
+	}
+
+	result = map[string]string{"name": "unchanged"}
+	if err := client.GET("/skipped").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["name"] != "unchanged" {
+		t.Errorf("Expected result to be left untouched, got %v", result)
+	}
+
+	err := client.GET("/missing").Do(&result)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("Expected 404 APIError, got %v", err)
+	}
+
+	if hits != 0 {
+		t.Errorf("Expected no request to reach the server, got %d", hits)
+	}
+	if !reflect.DeepEqual(seen, []int{200, 204, 404}) {
+		t.Errorf("Expected response middleware to see [200 204 404], got %v", seen)
+	}
+}
+
+func TestClient_BasicAuthMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Basic ") {
+			t.Fatalf("Expected Basic Authorization header, got '%s'", auth)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "Basic "))
+		if err != nil {
+			t.Fatalf("Failed to decode credentials: %v", err)
+		}
+		if string(decoded) != "user:pass" {
+			t.Errorf("Expected credentials 'user:pass', got '%s'", decoded)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		&Config{
+			BaseURL: server.URL,
+			Timeout: 5 * time.Second,
+		},
+		WithMiddleware(BasicAuthMiddleware("user", "pass")),
+	)
+
+	err := client.GET("/api/v1/test").Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestClient_TokenMiddleware(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	calls := 0
+	provider := func(ctx context.Context) (string, error) {
+		if ctx == nil {
+			t.Error("Expected provider to receive the request context")
+		}
+		calls++
+		if calls > 2 {
+			return "", errors.New("refresh failed")
+		}
+		return fmt.Sprintf("token-%d", calls), nil
+	}
+
+	client := NewClient(
+		&Config{
+			BaseURL: server.URL,
+			Timeout: 5 * time.Second,
+		},
+		WithMiddleware(TokenMiddleware(provider)),
+	)
+
+	for i := 0; i < 2; i++ {
+		if err := client.GET("/api/v1/test").Do(nil); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+	if len(received) != 2 || received[0] != "Bearer token-1" || received[1] != "Bearer token-2" {
+		t.Errorf("Expected fresh tokens per request, got %v", received)
+	}
+
+	err := client.GET("/api/v1/test").Do(nil)
+	if err == nil || !strings.Contains(err.Error(), "refresh failed") {
+		t.Errorf("Expected provider error, got %v", err)
+	}
+	if len(received) != 2 {
+		t.Errorf("Expected request to be aborted, server saw %d requests", len(received))
+	}
+}
+
+func TestClient_DoResponse_Trailers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "success"})
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var result map[string]string
+	resp, err := client.GET("/api/v1/test").DoResponse(&result)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if result["message"] != "success" {
+		t.Errorf("Expected message 'success', got '%s'", result["message"])
+	}
+	if got := resp.Trailers.Get("Grpc-Status"); got != "0" {
+		t.Errorf("Expected trailer Grpc-Status '0', got '%s'", got)
+	}
+}
+
+func TestClient_WithAccept(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(*RequestBuilder) *RequestBuilder
+		expected string
+	}{
+		{"json", (*RequestBuilder).WithAcceptJSON, "application/json"},
+		{"xml", (*RequestBuilder).WithAcceptXML, "application/xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Accept"); got != tt.expected {
+					t.Errorf("Expected Accept '%s', got '%s'", tt.expected, got)
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := NewClient(&Config{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			})
+
+			if err := tt.build(client.GET("/api/v1/test")).Do(nil); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_WithMethodOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+		if got := r.Header.Get("X-HTTP-Method-Override"); got != http.MethodDelete {
+			t.Errorf("Expected X-HTTP-Method-Override DELETE, got '%s'", got)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMethodOverride())
+
+	if err := client.DELETE("/api/v1/users/1").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestClient_WithSuccessValidator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			_, _ = w.Write([]byte(`{"ok":true,"data":"value"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":false,"error":"quota exceeded"}`))
+	}))
+	defer server.Close()
+
+	type envelope struct {
+		OK    bool   `json:"ok"`
+		Data  string `json:"data"`
+		Error string `json:"error"`
+	}
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithSuccessValidator(func(_ *http.Response, body []byte) error {
+		var env envelope
+		if err := json.Unmarshal(body, &env); err != nil {
+			return err
+		}
+		if !env.OK {
+			return errors.New(env.Error)
+		}
+		return nil
+	}))
+
+	var result envelope
+	err := client.GET("/fail").Do(&result)
+	if err == nil || err.Error() != "quota exceeded" {
+		t.Fatalf("Expected 'quota exceeded' error, got %v", err)
+	}
+
+	if err := client.GET("/ok").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result.Data != "value" {
+		t.Errorf("Expected data 'value', got '%s'", result.Data)
+	}
+}
+
+func TestClient_AddHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values := r.Header.Values("X-Forwarded-For")
+		if len(values) != 2 || values[0] != "10.0.0.1" || values[1] != "10.0.0.2" {
+			t.Errorf("Expected two X-Forwarded-For values, got %v", values)
+		}
+		if got := r.Header.Values("X-Single"); len(got) != 1 || got[0] != "second" {
+			t.Errorf("Expected WithHeader to replace values, got %v", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.GET("/api/v1/test").
+		AddHeader("X-Forwarded-For", "10.0.0.1").
+		AddHeader("X-Forwarded-For", "10.0.0.2").
+		WithHeader("X-Single", "first").
+		WithHeader("X-Single", "second").
+		Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestClient_WithBaseContext(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	baseCtx, cancel := context.WithCancel(context.Background())
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithBaseContext(baseCtx))
+
+	if err := client.GET("/api/v1/test").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	cancel()
+
+	err := client.GET("/api/v1/test").Do(nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// A per-request context still overrides the base context
+	if err := client.GET("/api/v1/test").WithContext(context.Background()).Do(nil); err != nil {
+		t.Fatalf("Request with explicit context failed: %v", err)
+	}
+
+	if hits != 2 {
+		t.Errorf("Expected 2 requests to reach the server, got %d", hits)
+	}
+}
+
+func TestClient_DoWithRaw(t *testing.T) {
+	payload := `{"id":"123","name":"test"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var result struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	raw, err := client.GET("/api/v1/test").DoWithRaw(&result)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if string(raw) != payload {
+		t.Errorf("Expected raw body %s, got %s", payload, raw)
+	}
+	if result.ID != "123" || result.Name != "test" {
+		t.Errorf("Response not decoded correctly, got: %+v", result)
+	}
+}
+
+func TestClient_WithResultValidator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"success":false,"status":"rejected"}`))
+	}))
+	defer server.Close()
+
+	type statusResult struct {
+		Success bool   `json:"success"`
+		Status  string `json:"status"`
+	}
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithResultValidator(func(result interface{}) error {
+		if r, ok := result.(*statusResult); ok && !r.Success {
+			return errors.New("operation " + r.Status)
+		}
+		return nil
+	}))
+
+	var result statusResult
+	err := client.GET("/api/v1/test").Do(&result)
+	if err == nil || err.Error() != "operation rejected" {
+		t.Fatalf("Expected 'operation rejected' error, got %v", err)
+	}
+	if result.Status != "rejected" {
+		t.Errorf("Expected result to be decoded, got %+v", result)
+	}
+}
+
+func TestClient_WithDefaultRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithDefaultRequestTimeout(50*time.Millisecond))
+
+	err := client.GET("/api/v1/test").Do(nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// An explicit deadline takes precedence over the default
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.GET("/api/v1/test").WithContext(ctx).Do(nil); err != nil {
+		t.Errorf("Request with explicit deadline failed: %v", err)
+	}
+}
+
+func TestClient_WithForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+			t.Errorf("Expected form Content-Type, got %s", ct)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm failed: %v", err)
+		}
+		if got := r.PostForm["tag"]; len(got) != 2 || got[0] != "a" || got[1] != "b&c" {
+			t.Errorf("Expected tag values [a b&c], got %v", got)
+		}
+		if got := r.PostForm.Get("name"); got != "John Doe" {
+			t.Errorf("Expected name 'John Doe', got '%s'", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.POST("/api/v1/form").
+		WithForm(url.Values{"name": {"John Doe"}, "tag": {"a", "b&c"}}).
+		Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestClient_WithFormMap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm failed: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("Expected grant_type 'client_credentials', got '%s'", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.POST("/oauth/token").
+		WithFormMap(map[string]string{"grant_type": "client_credentials"}).
+		Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestClient_WithNoRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/target" {
+			t.Error("Redirect should not be followed")
+		}
+		http.Redirect(w, r, "/target", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithNoRedirects())
+
+	var result map[string]string
+	resp, err := client.GET("/start").DoResponse(&result)
+	if err != nil {
+		t.Fatalf("Expected no error for redirect, got %v", err)
+	}
+
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("Expected status 302, got %d", resp.StatusCode)
+	}
+	if resp.Location != "/target" {
+		t.Errorf("Expected Location '/target', got '%s'", resp.Location)
+	}
+}
+
+func TestClient_XML(t *testing.T) {
+	type User struct {
+		XMLName xml.Name `xml:"user"`
+		ID      string   `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/xml" {
+			t.Errorf("Expected Content-Type application/xml, got %s", ct)
+		}
+
+		var req User
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode XML request: %v", err)
+		}
+		if req.Name != "John" {
+			t.Errorf("Expected name 'John', got '%s'", req.Name)
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(`<user id="123"><name>John</name></user>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var result User
+	err := client.POST("/api/v1/users").
+		WithXML(User{Name: "John"}).
+		DoXML(&result)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if result.ID != "123" || result.Name != "John" {
+		t.Errorf("Response not decoded correctly, got: %+v", result)
+	}
+}
+
+func TestClient_DoRawTargets(t *testing.T) {
+	const payload = "id,name\n1,John\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		var result []byte
+		if err := client.GET("/export").Do(&result); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if string(result) != payload {
+			t.Errorf("Expected %q, got %q", payload, result)
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		var result string
+		if err := client.GET("/export").Do(&result); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if result != payload {
+			t.Errorf("Expected %q, got %q", payload, result)
+		}
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := client.GET("/export").Do(&buf); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if buf.String() != payload {
+			t.Errorf("Expected %q, got %q", payload, buf.String())
+		}
+	})
+}
+
+func TestClient_DoStream(t *testing.T) {
+	const size = 8 << 20
+	chunk := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		for written := 0; written < size; written += len(chunk) {
+			_, _ = w.Write(chunk)
+		}
+	}))
+	defer server.Close()
+
+	var debugBuf bytes.Buffer
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithResponseMiddleware(DebugResponseMiddleware(&DebugOptions{
+		Writer:       &debugBuf,
+		ShowBody:     true,
+		MaxBodyBytes: 16,
+	})))
+
+	var counter countingWriter
+	n, err := client.GET("/download").DoStream(&counter)
+	if err != nil {
+		t.Fatalf("DoStream failed: %v", err)
+	}
+
+	if n != size || counter.n != size {
+		t.Errorf("Expected %d bytes, got %d (writer saw %d)", size, n, counter.n)
+	}
+	if !strings.Contains(debugBuf.String(), "200 OK") {
+		t.Errorf("Expected response middleware to run, got: %s", debugBuf.String())
+	}
+}
+
+func TestClient_DoEmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+		case "/empty":
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusOK)
+		default:
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	for _, path := range []string{"/no-content", "/empty"} {
+		result := map[string]string{"status": "unchanged"}
+		if err := client.DELETE(path).Do(&result); err != nil {
+			t.Fatalf("Request to %s failed: %v", path, err)
+		}
+		if result["status"] != "unchanged" {
+			t.Errorf("Expected result to be left as is for %s, got %v", path, result)
+		}
+	}
+
+	var result map[string]string
+	if err := client.GET("/json").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["status"] != "ok" {
+		t.Errorf("Expected status 'ok', got '%s'", result["status"])
+	}
+}
+
+func TestClient_DoBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("no such file"))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("raw data"))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	data, status, err := client.GET("/file").DoBytes()
+	if err != nil {
+		t.Fatalf("DoBytes failed: %v", err)
+	}
+	if string(data) != "raw data" || status != http.StatusAccepted {
+		t.Errorf("Expected 'raw data' with status 202, got '%s' with %d", data, status)
+	}
+
+	data, status, err = client.GET("/missing").DoBytes()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("Expected not found APIError, got %v", err)
+	}
+	if string(data) != "no such file" || status != http.StatusNotFound {
+		t.Errorf("Expected 'no such file' with status 404, got '%s' with %d", data, status)
+	}
+}
+
+func TestClient_DoWithMeta(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "42")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"123","name":"alice"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	meta, err := client.POST("/users").DoWithMeta(&user)
+	if err != nil {
+		t.Fatalf("DoWithMeta failed: %v", err)
+	}
+	if user.ID != "123" || user.Name != "alice" {
+		t.Errorf("Unexpected decoded result: %+v", user)
+	}
+	if meta.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", meta.StatusCode)
+	}
+	if got := meta.Header.Get("X-Total-Count"); got != "42" {
+		t.Errorf("Expected X-Total-Count '42', got '%s'", got)
+	}
+	if string(meta.Body) != `{"id":"123","name":"alice"}` {
+		t.Errorf("Unexpected raw body: %s", meta.Body)
+	}
+}
+
+func TestClient_DoStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	status, err := client.POST("/ping").DoStatus()
+	if err != nil {
+		t.Fatalf("DoStatus failed: %v", err)
+	}
+	if status != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", status)
+	}
+
+	status, err = client.DELETE("/missing").DoStatus()
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", status)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("Expected not found APIError, got %v", err)
+	}
+}
+
+func TestClient_WithUnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "httpclient")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	socket := filepath.Join(dir, "api.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"host": r.Host, "path": r.URL.Path})
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: "http://local.service/v1",
+		Timeout: 5 * time.Second,
+	}, WithUnixSocket(socket))
+
+	var result map[string]string
+	if err := client.GET("/status").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if result["host"] != "local.service" {
+		t.Errorf("Expected host 'local.service', got '%s'", result["host"])
+	}
+	if result["path"] != "/v1/status" {
+		t.Errorf("Expected path '/v1/status', got '%s'", result["path"])
+	}
+}
+
+func TestClient_AbsoluteURL(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path, "cursor": r.URL.Query().Get("cursor")})
+	}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected request to go to the other server, got %s", r.URL)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL + "/api/v1",
+		Timeout: 5 * time.Second,
+	})
+
+	var result map[string]string
+	err := client.GET(other.URL + "/items?cursor=abc").
+		WithBasePath("/v2").
+		Do(&result)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if result["path"] != "/items" {
+		t.Errorf("Expected path '/items', got '%s'", result["path"])
+	}
+	if result["cursor"] != "abc" {
+		t.Errorf("Expected cursor 'abc', got '%s'", result["cursor"])
+	}
+}
+
+func TestClient_RequestHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	var startReq, finishReq *http.Request
+	var finishResp *http.Response
+	var finishErr error
+	var elapsed time.Duration
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	},
+		WithOnRequestStart(func(req *http.Request) {
+			startReq = req
+		}),
+		WithOnRequestFinish(func(req *http.Request, resp *http.Response, err error, d time.Duration) {
+			finishReq, finishResp, finishErr, elapsed = req, resp, err, d
+		}))
+
+	if err := client.POST("/jobs").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if startReq == nil || startReq.URL.Path != "/jobs" || startReq.Method != http.MethodPost {
+		t.Fatalf("Expected start hook with POST /jobs, got %v", startReq)
+	}
+	if finishReq != startReq {
+		t.Errorf("Expected finish hook to receive the same request")
+	}
+	if finishErr != nil {
+		t.Errorf("Expected no error, got %v", finishErr)
+	}
+	if finishResp == nil || finishResp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 response in finish hook, got %v", finishResp)
+	}
+	if elapsed < 20*time.Millisecond {
+		t.Errorf("Expected duration of at least 20ms, got %v", elapsed)
+	}
+}
+
+func TestClient_WithProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		_ = json.NewEncoder(w).Encode(map[string]string{"via": "proxy"})
+	}))
+	defer proxy.Close()
+
+	client := NewClient(&Config{
+		BaseURL: "http://api.example.invalid/v1",
+		Timeout: 5 * time.Second,
+	}, WithProxy(proxy.URL))
+
+	var result map[string]string
+	if err := client.GET("/users").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if len(proxied) != 1 || proxied[0] != "http://api.example.invalid/v1/users" {
+		t.Errorf("Expected the proxy to receive the request, got %v", proxied)
+	}
+	if result["via"] != "proxy" {
+		t.Errorf("Expected response from the proxy, got %v", result)
+	}
+
+	err := NewClient(&Config{BaseURL: "http://api.example.invalid"}, WithProxy("::bad")).GET("/").Do(nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+		t.Errorf("Expected invalid proxy URL error, got %v", err)
+	}
+}
+
+func TestClient_WithDefaultHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"tenant": r.Header.Get("X-Tenant-ID"),
+			"accept": r.Header.Get("Accept"),
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithDefaultHeaders(map[string]string{
+		"X-Tenant-ID": "acme",
+		"Accept":      "application/json",
+	}))
+
+	var result map[string]string
+	if err := client.GET("/default").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["tenant"] != "acme" || result["accept"] != "application/json" {
+		t.Errorf("Expected default headers, got %v", result)
+	}
+
+	if err := client.GET("/override").WithHeader("X-Tenant-ID", "globex").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["tenant"] != "globex" {
+		t.Errorf("Expected per-request header to win, got '%s'", result["tenant"])
+	}
+	if result["accept"] != "application/json" {
+		t.Errorf("Expected other defaults to be kept, got '%s'", result["accept"])
+	}
+
+	// Overrides must not leak into later requests
+	if err := client.GET("/default").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["tenant"] != "acme" {
+		t.Errorf("Expected default header on a new request, got '%s'", result["tenant"])
+	}
+}
+
+func TestClient_WithDefaultQuery(t *testing.T) {
+	var received []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Query())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithDefaultQuery(map[string]string{"api_version": "2024-01-01", "apikey": "secret"}))
+
+	requests := []*RequestBuilder{
+		client.GET("/items"),
+		client.GET("/items").WithQuery("api_version", "2025-06-01").WithQuery("page", "2"),
+		client.GET("/items").WithQuery("apikey", "a").WithQuery("apikey", "b"),
+	}
+	for i, req := range requests {
+		if err := req.Do(nil); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+
+	expected := []url.Values{
+		{"api_version": {"2024-01-01"}, "apikey": {"secret"}},
+		{"api_version": {"2025-06-01"}, "apikey": {"secret"}, "page": {"2"}},
+		{"api_version": {"2024-01-01"}, "apikey": {"a", "b"}},
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(received[i], expected[i]) {
+			t.Errorf("Request %d: expected query %v, got %v", i, expected[i], received[i])
+		}
+	}
+}
+
+func TestClient_WithBasePath(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+" "+r.Header.Get("X-Tenant-ID"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL + "/v1",
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(HeaderMiddleware(map[string]string{"X-Tenant-ID": "acme"})))
+
+	users := client.WithBasePath("/users")
+	if err := users.GET("/42").Do(nil); err != nil {
+		t.Fatalf("Sub-client request failed: %v", err)
+	}
+	if err := client.GET("/orders").Do(nil); err != nil {
+		t.Fatalf("Original client request failed: %v", err)
+	}
+
+	expected := []string{"/v1/users/42 acme", "/v1/orders acme"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+	if users.(*HTTPClient).httpClient != client.(*HTTPClient).httpClient {
+		t.Error("Expected the sub-client to share the HTTP client")
+	}
+}
+
+// countingWriter counts bytes written without storing them
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,82 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Codec encodes request bodies and decodes response bodies of one media type
+type Codec interface {
+	// ContentType returns the media type handled by the codec, e.g. "application/json"
+	ContentType() string
+	// Encode serializes v into a request body
+	Encode(v interface{}) ([]byte, error)
+	// Decode deserializes a response body into v
+	Decode(r io.Reader, v interface{}) error
+}
+
+// Built-in codecs
+var (
+	JSONCodec Codec = jsonCodec{}
+	XMLCodec  Codec = xmlCodec{}
+)
+
+// jsonCodec implements Codec with encoding/json
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return "application/json" }
+
+func (jsonCodec) Encode(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error { return json.NewDecoder(r).Decode(v) }
+
+// xmlCodec implements Codec with encoding/xml
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string { return "application/xml" }
+
+func (xmlCodec) Encode(v interface{}) ([]byte, error) { return xml.Marshal(v) }
+
+func (xmlCodec) Decode(r io.Reader, v interface{}) error { return xml.NewDecoder(r).Decode(v) }
+
+// WithCodec registers a codec for its content type, replacing any codec
+// registered for the same type. Do decodes responses with the codec matching
+// their Content-Type and falls back to JSON. Only JSON is registered by default;
+// register XMLCodec to decode XML responses in Do.
+func WithCodec(codec Codec) Option {
+	return func(c *HTTPClient) {
+		if c.codecs == nil {
+			c.codecs = make(map[string]Codec)
+		}
+		c.codecs[mediaType(codec.ContentType())] = codec
+	}
+}
+
+// codecFor returns the codec registered for a Content-Type value
+func (c *HTTPClient) codecFor(contentType string) (Codec, bool) {
+	codec, ok := c.codecs[mediaType(contentType)]
+	if !ok && mediaType(contentType) == mediaType(JSONCodec.ContentType()) {
+		return JSONCodec, true
+	}
+	return codec, ok
+}
+
+// responseCodec returns the codec for a response Content-Type, falling back to JSON
+func (c *HTTPClient) responseCodec(contentType string) Codec {
+	if codec, ok := c.codecFor(contentType); ok {
+		return codec
+	}
+	codec, _ := c.codecFor(JSONCodec.ContentType())
+	return codec
+}
+
+// mediaType returns the lower-case media type of a Content-Type value, without parameters
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
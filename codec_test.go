@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// kvCodec encodes map[string]string values as "key=value" lines
+type kvCodec struct{}
+
+func (kvCodec) ContentType() string { return "text/x-kv" }
+
+func (kvCodec) Encode(v interface{}) ([]byte, error) {
+	m, ok := v.(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+	var sb strings.Builder
+	for k, val := range m {
+		sb.WriteString(k + "=" + val + "\n")
+	}
+	return []byte(sb.String()), nil
+}
+
+func (kvCodec) Decode(r io.Reader, v interface{}) error {
+	m, ok := v.(*map[string]string)
+	if !ok {
+		return fmt.Errorf("unsupported type %T", v)
+	}
+	*m = map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if k, val, ok := strings.Cut(scanner.Text(), "="); ok {
+			(*m)[k] = val
+		}
+	}
+	return scanner.Err()
+}
+
+func TestClient_WithCodec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "text/x-kv" {
+			t.Errorf("Expected Content-Type text/x-kv, got %s", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "name=test\n" {
+			t.Errorf("Unexpected request body %q", body)
+		}
+
+		w.Header().Set("Content-Type", "text/x-kv; charset=utf-8")
+		_, _ = w.Write([]byte("id=123\nname=test\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithCodec(kvCodec{}))
+
+	var result map[string]string
+	err := client.POST("/api/v1/items").
+		WithEncodedBody("text/x-kv", map[string]string{"name": "test"}).
+		Do(&result)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if result["id"] != "123" || result["name"] != "test" {
+		t.Errorf("Response not decoded with custom codec, got: %v", result)
+	}
+}
+
+func TestClient_CodecFallsBackToJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(`{"message":"success"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithCodec(kvCodec{}))
+
+	var result map[string]string
+	if err := client.GET("/api/v1/test").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["message"] != "success" {
+		t.Errorf("Expected JSON fallback, got: %v", result)
+	}
+}
+
+func TestRequestBuilder_WithEncodedBody_Unregistered(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	_, err := client.POST("/api/v1/items").
+		WithEncodedBody("application/x-protobuf", struct{}{}).
+		BuildRequest()
+	if err == nil {
+		t.Fatal("Expected error for unregistered content type")
+	}
+}
@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// ConnStats counts the connections obtained by a client's requests, telling
+// apart newly dialed connections from ones reused from the idle pool. It is
+// safe for concurrent use.
+type ConnStats struct {
+	newConns    atomic.Int64
+	reusedConns atomic.Int64
+	idleConns   atomic.Int64
+}
+
+// NewConns returns the number of requests that dialed a new connection
+func (s *ConnStats) NewConns() int64 {
+	return s.newConns.Load()
+}
+
+// ReusedConns returns the number of requests that reused an existing connection
+func (s *ConnStats) ReusedConns() int64 {
+	return s.reusedConns.Load()
+}
+
+// IdleConns returns the number of reused connections that were taken from the idle pool
+func (s *ConnStats) IdleConns() int64 {
+	return s.idleConns.Load()
+}
+
+// gotConn records a connection obtained for a request
+func (s *ConnStats) gotConn(info httptrace.GotConnInfo) {
+	if !info.Reused {
+		s.newConns.Add(1)
+		return
+	}
+	s.reusedConns.Add(1)
+	if info.WasIdle {
+		s.idleConns.Add(1)
+	}
+}
+
+// ConnStatsMiddleware creates a middleware that counts the connections used by
+// each request in stats, including those of retried attempts. It composes with
+// an httptrace.ClientTrace already set on the request context.
+func ConnStatsMiddleware(stats *ConnStats) Middleware {
+	return func(req *http.Request) error {
+		ctx := httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+			GotConn: stats.gotConn,
+		})
+		*req = *req.WithContext(ctx)
+		return nil
+	}
+}
+
+// WithConnStats counts the connections used by every request in stats, for
+// monitoring connection reuse
+//
+// Example usage:
+//
+//	stats := &httpclient.ConnStats{}
+//	client := httpclient.NewClient(config, httpclient.WithConnStats(stats))
+//	// ...
+//	log.Printf("new=%d reused=%d", stats.NewConns(), stats.ReusedConns())
+func WithConnStats(stats *ConnStats) Option {
+	return func(c *HTTPClient) {
+		c.middleware = append(c.middleware, ConnStatsMiddleware(stats))
+	}
+}
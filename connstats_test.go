@@ -0,0 +1,38 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithConnStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	stats := &ConnStats{}
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithConnStats(stats))
+
+	for i := 0; i < 5; i++ {
+		var body string
+		if err := client.GET("/ping").Do(&body); err != nil {
+			t.Fatalf("Request %d failed: %v", i+1, err)
+		}
+	}
+
+	if got := stats.NewConns(); got != 1 {
+		t.Errorf("Expected 1 new connection, got %d", got)
+	}
+	if got := stats.ReusedConns(); got != 4 {
+		t.Errorf("Expected 4 reused connections, got %d", got)
+	}
+	if got := stats.IdleConns(); got != 4 {
+		t.Errorf("Expected 4 connections from the idle pool, got %d", got)
+	}
+}
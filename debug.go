@@ -2,32 +2,66 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
+	"time"
 )
 
+// DefaultDebugMaxBodyBytes is the default number of body bytes printed by the debug middleware
+const DefaultDebugMaxBodyBytes = 64 * 1024
+
+// UnlimitedDebugBody, set as DebugOptions.MaxBodyBytes, prints bodies in full.
+// Zero cannot mean unlimited, since it is what an unset field holds.
+const UnlimitedDebugBody = -1
+
 // DebugOptions configures debug output behavior
 type DebugOptions struct {
 	Color    bool      // Enable color output (ANSI color codes)
 	Writer   io.Writer // Writer to output debug information (default: os.Stdout)
 	ShowBody bool      // Controls whether to print request/response body
+
+	// MaxBodyBytes limits how much of a body is printed (default: DefaultDebugMaxBodyBytes).
+	// Set UnlimitedDebugBody, or any negative value, to print bodies in full.
+	MaxBodyBytes int
+
+	// AsCurl prints each request as an equivalent curl command instead of an
+	// HTTP-style dump. Responses are printed as usual.
+	AsCurl bool
+
+	// RedactHeaders lists headers whose values are printed as "***", matched
+	// case-insensitively (default: DefaultRedactHeaders). Set an empty, non-nil
+	// slice to print all values.
+	RedactHeaders []string
 }
 
+// DefaultRedactHeaders are the headers redacted in debug output by default
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "X-API-Key"}
+
 // applyDefaults applies default values to DebugOptions
 func (o *DebugOptions) applyDefaults() *DebugOptions {
 	if o == nil {
 		return &DebugOptions{
-			Color:    true,
-			Writer:   os.Stdout,
-			ShowBody: true,
+			Color:         true,
+			Writer:        os.Stdout,
+			ShowBody:      true,
+			MaxBodyBytes:  DefaultDebugMaxBodyBytes,
+			RedactHeaders: DefaultRedactHeaders,
 		}
 	}
 	if o.Writer == nil {
 		o.Writer = os.Stdout
 	}
+	if o.MaxBodyBytes == 0 {
+		o.MaxBodyBytes = DefaultDebugMaxBodyBytes
+	}
+	if o.RedactHeaders == nil {
+		o.RedactHeaders = DefaultRedactHeaders
+	}
 	return o
 }
 
@@ -51,14 +85,71 @@ func DebugMiddleware(opts *DebugOptions) Middleware {
 	opts = opts.applyDefaults()
 
 	return func(req *http.Request) error {
-		printRequestLine(opts.Writer, req)
-		printHeaders(opts.Writer, opts.Color, ">", req.Header)
+		return printRequest(opts.Writer, opts, req)
+	}
+}
+
+// printRequest prints the request line, headers and optionally the body to w
+func printRequest(w io.Writer, opts *DebugOptions, req *http.Request) error {
+	if opts.AsCurl {
+		return printCurl(w, opts, req)
+	}
+
+	printRequestLine(w, req)
+	printHeaders(w, opts.Color, ">", req.Header, opts.RedactHeaders)
+
+	if opts.ShowBody && req.Body != nil {
+		return printBody(w, opts.Color, opts.MaxBodyBytes, req.Body, &req.Body)
+	}
+	return nil
+}
 
-		if opts.ShowBody && req.Body != nil {
-			return printBody(opts.Writer, opts.Color, req.Body, &req.Body)
+// printCurl prints an equivalent curl command for the request to w.
+// Redacted header values are printed as "***".
+func printCurl(w io.Writer, opts *DebugOptions, req *http.Request) error {
+	parts := []string{"curl", "-X", req.Method, shellQuote(req.URL.String())}
+
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			if isRedacted(k, opts.RedactHeaders) {
+				v = redactedValue
+			}
+			parts = append(parts, "-H", shellQuote(k+": "+v))
+		}
+	}
+
+	var truncated bool
+	if opts.ShowBody && req.Body != nil && req.Body != http.NoBody {
+		body, more, err := peekBody(opts.MaxBodyBytes, req.Body, &req.Body)
+		if err != nil {
+			return err
+		}
+		truncated = more
+		if len(body) > 0 {
+			// --data-raw keeps a leading @ from being read as a file name
+			flag := "-d"
+			if body[0] == '@' {
+				flag = "--data-raw"
+			}
+			parts = append(parts, flag, shellQuote(string(body)))
 		}
-		return nil
 	}
+
+	_, _ = fmt.Fprintln(w, strings.Join(parts, " "))
+	if truncated {
+		_, _ = fmt.Fprintf(w, "# body truncated at %d bytes\n", opts.MaxBodyBytes)
+	}
+	return nil
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // ANSI color codes
@@ -85,20 +176,82 @@ func colorBlue(s string) string {
 //	    httpclient.WithMiddleware(httpclient.DebugMiddleware(nil)),
 //	    httpclient.WithResponseMiddleware(httpclient.DebugResponseMiddleware(nil)))
 //
-// Note: This middleware reads the entire response body. For large responses,
-// consider disabling ShowBody or using a custom filter.
+// Note: The client buffers the response body for response middleware. Only the
+// first MaxBodyBytes of it are printed; consider disabling ShowBody for large responses.
 func DebugResponseMiddleware(opts *DebugOptions) ResponseMiddleware {
 	opts = opts.applyDefaults()
 
 	return func(resp *http.Response) error {
-		_, _ = fmt.Fprintf(opts.Writer, "< %s %s\n", resp.Proto, resp.Status)
-		printHeaders(opts.Writer, opts.Color, "<", resp.Header)
+		return printResponse(opts.Writer, opts, resp)
+	}
+}
 
-		if opts.ShowBody && resp.Body != nil {
-			return printBody(opts.Writer, opts.Color, resp.Body, &resp.Body)
+// printResponse prints the elapsed time, status line, headers and optionally the body to w
+func printResponse(w io.Writer, opts *DebugOptions, resp *http.Response) error {
+	if resp.Request != nil {
+		if id, ok := RequestIDFromContext(resp.Request.Context()); ok {
+			_, _ = fmt.Fprintf(w, "* Request ID: %s\n", id)
+		}
+		if start, ok := RequestStartFromContext(resp.Request.Context()); ok {
+			_, _ = fmt.Fprintf(w, "* Time: %s\n", time.Since(start))
 		}
-		return nil
 	}
+	_, _ = fmt.Fprintf(w, "< %s %s\n", resp.Proto, resp.Status)
+	printHeaders(w, opts.Color, "<", resp.Header, opts.RedactHeaders)
+
+	if opts.ShowBody && resp.Body != nil {
+		return printBody(w, opts.Color, opts.MaxBodyBytes, resp.Body, &resp.Body)
+	}
+	return nil
+}
+
+// WithDebugOnError logs requests and responses like DebugMiddleware and
+// DebugResponseMiddleware, but buffers the output of each call and only writes
+// it when the call fails with a non-2xx status or a transport error. Like other
+// middleware, the request is logged as seen after the middleware added before it.
+func WithDebugOnError(opts *DebugOptions) Option {
+	opts = opts.applyDefaults()
+
+	return func(c *HTTPClient) {
+		c.debugOnError = opts
+		c.middleware = append(c.middleware, func(req *http.Request) error {
+			if buf := debugBufferFromContext(req.Context()); buf != nil {
+				return printRequest(&buf.buf, opts, req)
+			}
+			return nil
+		})
+		c.responseMiddleware = append(c.responseMiddleware, func(resp *http.Response) error {
+			if resp.Request == nil {
+				return nil
+			}
+			buf := debugBufferFromContext(resp.Request.Context())
+			if buf == nil {
+				return nil
+			}
+			err := printResponse(&buf.buf, opts, resp)
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				buf.flush()
+			}
+			return err
+		})
+	}
+}
+
+// debugBuffer holds the debug output of one call until it is known to have failed
+type debugBuffer struct {
+	buf bytes.Buffer
+	w   io.Writer
+}
+
+// flush writes the buffered output
+func (d *debugBuffer) flush() {
+	_, _ = d.buf.WriteTo(d.w)
+}
+
+// debugBufferFromContext returns the debug buffer of the call, if any
+func debugBufferFromContext(ctx context.Context) *debugBuffer {
+	buf, _ := ctx.Value(debugBufferKey).(*debugBuffer)
+	return buf
 }
 
 // printRequestLine prints HTTP request line
@@ -110,11 +263,14 @@ func printRequestLine(w io.Writer, req *http.Request) {
 	_, _ = fmt.Fprintf(w, "> %s %s %s\n", req.Method, path, req.Proto)
 }
 
-// printHeaders prints HTTP headers with optional color
-func printHeaders(w io.Writer, useColor bool, prefix string, headers http.Header) {
+// printHeaders prints HTTP headers with optional color, masking the values of redacted headers
+func printHeaders(w io.Writer, useColor bool, prefix string, headers http.Header, redact []string) {
 	for key, values := range headers {
 		headerName := key
 		headerValue := strings.Join(values, ", ")
+		if isRedacted(key, redact) {
+			headerValue = redactedValue
+		}
 
 		if useColor {
 			headerName = colorPurple(key)
@@ -126,21 +282,76 @@ func printHeaders(w io.Writer, useColor bool, prefix string, headers http.Header
 	_, _ = fmt.Fprintf(w, "%s\n", prefix)
 }
 
+// redactedValue replaces the values of redacted headers
+const redactedValue = "***"
+
+// redactHeaders returns a copy of headers with the values of redacted headers masked
+func redactHeaders(headers http.Header, redact []string) http.Header {
+	redacted := headers.Clone()
+	for k, values := range redacted {
+		if isRedacted(k, redact) {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return redacted
+}
+
+// isRedacted reports whether the header name is in the redact list
+func isRedacted(name string, redact []string) bool {
+	for _, r := range redact {
+		if strings.EqualFold(r, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // printBody reads, prints and restores HTTP body
-// The bodyPtr parameter is updated to point to the restored body
-func printBody(w io.Writer, _ bool, body io.ReadCloser, bodyPtr *io.ReadCloser) error {
-	bodyBytes, err := io.ReadAll(body)
+// The bodyPtr parameter is updated to point to the restored body.
+// At most maxBytes are read and printed when maxBytes is positive.
+func printBody(w io.Writer, _ bool, maxBytes int, body io.ReadCloser, bodyPtr *io.ReadCloser) error {
+	bodyBytes, truncated, err := peekBody(maxBytes, body, bodyPtr)
 	if err != nil {
-		return fmt.Errorf("failed to read body for debug: %w", err)
+		return err
 	}
-	_ = body.Close()
-
-	// Restore body immediately
-	*bodyPtr = io.NopCloser(bytes.NewReader(bodyBytes))
 
 	if len(bodyBytes) > 0 {
 		_, _ = fmt.Fprintln(w, string(bodyBytes))
+		if truncated {
+			_, _ = fmt.Fprintf(w, "... (body truncated at %d bytes)\n", maxBytes)
+		}
 		_, _ = fmt.Fprintln(w)
 	}
 	return nil
 }
+
+// peekBody reads up to maxBytes of body (all of it if maxBytes is not positive)
+// and restores it through bodyPtr, reporting whether more remained
+func peekBody(maxBytes int, body io.ReadCloser, bodyPtr *io.ReadCloser) ([]byte, bool, error) {
+	var reader io.Reader = body
+	if maxBytes > 0 {
+		reader = io.LimitReader(body, int64(maxBytes)+1)
+	}
+
+	bodyBytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read body for debug: %w", err)
+	}
+
+	truncated := maxBytes > 0 && len(bodyBytes) > maxBytes
+	if truncated {
+		// Restore body by replaying the consumed prefix ahead of the unread remainder
+		*bodyPtr = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(bodyBytes), body), body}
+		bodyBytes = bodyBytes[:maxBytes]
+	} else {
+		_ = body.Close()
+		// Restore body immediately
+		*bodyPtr = io.NopCloser(bytes.NewReader(bodyBytes))
+	}
+	return bodyBytes, truncated, nil
+}
@@ -2,6 +2,8 @@ package httpclient
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -388,3 +390,272 @@ func TestDebugResponseMiddleware_WithoutBody(t *testing.T) {
 		t.Errorf("Debug output should not contain body when ShowBody=false, got: %s", output)
 	}
 }
+
+func TestDebugResponseMiddleware_MaxBodyBytes(t *testing.T) {
+	largeBody := strings.Repeat("a", DefaultDebugMaxBodyBytes+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(largeBody))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		maxBodyBytes  int
+		wantTruncated bool
+	}{
+		{"default limit", 0, true},
+		{"unlimited", UnlimitedDebugBody, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			client := NewClient(&Config{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			}, WithResponseMiddleware(DebugResponseMiddleware(&DebugOptions{
+				Writer:       &buf,
+				ShowBody:     true,
+				MaxBodyBytes: tt.maxBodyBytes,
+			})))
+
+			resp, err := client.GET("/api/large").DoWithResponse()
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			body, _ := io.ReadAll(resp.Body)
+			if len(body) != len(largeBody) {
+				t.Errorf("Expected restored body of %d bytes, got %d", len(largeBody), len(body))
+			}
+
+			output := buf.String()
+			truncated := strings.Contains(output, "body truncated")
+			if truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated=%v, got output of %d bytes", tt.wantTruncated, len(output))
+			}
+			if tt.wantTruncated && strings.Contains(output, largeBody) {
+				t.Error("Expected body to be truncated in debug output")
+			}
+		})
+	}
+}
+
+func TestDebugResponseMiddleware_Time(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithResponseMiddleware(DebugResponseMiddleware(&DebugOptions{
+		Writer: &buf,
+	})))
+
+	if _, err := client.GET("/api/test").DoStatus(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	output := buf.String()
+	_, after, ok := strings.Cut(output, "* Time: ")
+	if !ok {
+		t.Fatalf("Debug output missing time line, got: %s", output)
+	}
+	line, _, _ := strings.Cut(after, "\n")
+	elapsed, err := time.ParseDuration(line)
+	if err != nil {
+		t.Fatalf("Expected parseable duration, got %q: %v", line, err)
+	}
+	if elapsed < 10*time.Millisecond {
+		t.Errorf("Expected elapsed time of at least 10ms, got %v", elapsed)
+	}
+}
+
+func TestWithDebugOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message":"database unavailable"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithDebugOnError(&DebugOptions{Writer: &buf, ShowBody: true}))
+
+	if err := client.POST("/ok").WithJSON(map[string]string{"name": "John"}).Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no debug output on success, got: %s", buf.String())
+	}
+
+	err := client.POST("/fail").WithJSON(map[string]string{"name": "Jane"}).Do(nil)
+	if err == nil {
+		t.Fatal("Expected error for 500 response")
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"> POST /fail HTTP/1.1",
+		`{"name":"Jane"}`,
+		"< HTTP/1.1 500 Internal Server Error",
+		`{"message":"database unavailable"}`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected debug output to contain %q, got: %s", expected, output)
+		}
+	}
+	if strings.Contains(output, "John") {
+		t.Errorf("Expected successful call to stay out of the output, got: %s", output)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "database unavailable" {
+		t.Errorf("Expected error body to survive debugging, got %v", err)
+	}
+}
+
+func TestWithDebugOnError_TransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(&Config{
+		BaseURL: url,
+		Timeout: 5 * time.Second,
+	}, WithDebugOnError(&DebugOptions{Writer: &buf}))
+
+	if err := client.GET("/down").Do(nil); err == nil {
+		t.Fatal("Expected transport error")
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "> GET /down HTTP/1.1") || !strings.Contains(output, "* Error:") {
+		t.Errorf("Expected request dump and error, got: %s", output)
+	}
+}
+
+func TestDebugMiddleware_RedactHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		redact   []string
+		hidden   []string
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			hidden:   []string{"secret-token", "api-key-value", "session=xyz"},
+			expected: []string{"Authorization: ***", "X-Api-Key: ***", "Cookie: ***", "X-Tenant: acme"},
+		},
+		{
+			name:     "custom list is case-insensitive",
+			redact:   []string{"x-tenant", "SET-COOKIE"},
+			hidden:   []string{"acme", "session=abc"},
+			expected: []string{"X-Tenant: ***", "Set-Cookie: ***", "Authorization: Bearer secret-token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := &DebugOptions{Writer: &buf, RedactHeaders: tt.redact}
+			client := NewClient(&Config{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			},
+				WithMiddleware(DebugMiddleware(opts)),
+				WithResponseMiddleware(DebugResponseMiddleware(opts)))
+
+			err := client.GET("/api/v1/test").
+				WithHeader("Authorization", "Bearer secret-token").
+				WithHeader("X-API-Key", "api-key-value").
+				WithHeader("Cookie", "session=xyz").
+				WithHeader("X-Tenant", "acme").
+				Do(nil)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			output := buf.String()
+			for _, s := range tt.hidden {
+				if strings.Contains(output, s) {
+					t.Errorf("Expected %q to be redacted, got: %s", s, output)
+				}
+			}
+			for _, s := range tt.expected {
+				if !strings.Contains(output, s) {
+					t.Errorf("Expected output to contain %q, got: %s", s, output)
+				}
+			}
+		})
+	}
+}
+
+func TestDebugMiddleware_AsCurl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"O'Brien"}` {
+			t.Errorf("Expected body to reach the server intact, got %s", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(DebugMiddleware(&DebugOptions{
+		Writer:   &buf,
+		ShowBody: true,
+		AsCurl:   true,
+	})))
+
+	err := client.POST("/api/v1/users").
+		WithHeader("Authorization", "Bearer secret-token").
+		WithQuery("notify", "true").
+		WithJSON(map[string]string{"name": "O'Brien"}).
+		Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "curl -X POST '"+server.URL+"/api/v1/users?notify=true'") {
+		t.Errorf("Expected output to start with the curl command, got: %s", output)
+	}
+	for _, expected := range []string{
+		`-H 'Authorization: ***'`,
+		`-H 'Content-Type: application/json'`,
+		`-d '{"name":"O'\''Brien"}'`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %s, got: %s", expected, output)
+		}
+	}
+	if strings.Contains(output, "secret-token") {
+		t.Errorf("Expected token to be redacted, got: %s", output)
+	}
+}
@@ -0,0 +1,156 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ContentDecoder wraps a compressed reader with one producing the decoded content
+type ContentDecoder func(io.Reader) (io.Reader, error)
+
+// contentDecoders holds the registered decoders keyed by lower-case Content-Encoding
+var (
+	contentDecodersMu sync.RWMutex
+	contentDecoders   = map[string]ContentDecoder{
+		"gzip":    gzipDecoder,
+		"x-gzip":  gzipDecoder,
+		"deflate": deflateDecoder,
+	}
+)
+
+// defaultDecompressEncodings are decoded transparently even without WithAutoDecompress
+var defaultDecompressEncodings = []string{"gzip", "x-gzip", "deflate"}
+
+// RegisterContentDecoder registers a decoder for a Content-Encoding such as
+// "br" or "zstd", replacing any existing one. gzip and deflate are built in.
+// It is typically called from an init function.
+func RegisterContentDecoder(encoding string, fn ContentDecoder) {
+	contentDecodersMu.Lock()
+	defer contentDecodersMu.Unlock()
+	contentDecoders[strings.ToLower(encoding)] = fn
+}
+
+// lookupContentDecoder returns the decoder registered for encoding, if any
+func lookupContentDecoder(encoding string) (ContentDecoder, bool) {
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+	fn, ok := contentDecoders[strings.ToLower(strings.TrimSpace(encoding))]
+	return fn, ok
+}
+
+// gzipDecoder decodes gzip content
+func gzipDecoder(r io.Reader) (io.Reader, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	return zr, nil
+}
+
+// deflateDecoder decodes deflate (zlib) content
+func deflateDecoder(r io.Reader) (io.Reader, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deflate reader: %w", err)
+	}
+	return zr, nil
+}
+
+// acceptEncoding returns the Accept-Encoding value for the encodings that have a decoder
+func acceptEncoding(encodings []string) string {
+	supported := make([]string, 0, len(encodings))
+	for _, enc := range encodings {
+		if _, ok := lookupContentDecoder(enc); ok {
+			supported = append(supported, enc)
+		}
+	}
+	return strings.Join(supported, ", ")
+}
+
+// decompressResponse replaces a compressed response body with its decoded content
+// when the Content-Encoding is a default one or one of the accepted encodings
+func decompressResponse(resp *http.Response, encodings []string) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" || !hasBody(resp) ||
+		(!containsFold(defaultDecompressEncodings, encoding) && !containsFold(encodings, encoding)) {
+		return nil
+	}
+
+	decode, ok := lookupContentDecoder(encoding)
+	if !ok {
+		return nil
+	}
+
+	decoded, err := decode(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	resp.Body = &decodedBody{Reader: decoded, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// hasBody reports whether resp may carry content. Decoders read the stream header
+// as soon as they are created, so they fail on the empty body of, say, a 204
+// that still names a Content-Encoding.
+func hasBody(resp *http.Response) bool {
+	switch {
+	case resp.Body == nil, resp.Body == http.NoBody, resp.ContentLength == 0:
+		return false
+	case resp.StatusCode == http.StatusNoContent, resp.StatusCode == http.StatusNotModified:
+		return false
+	case resp.Request != nil && resp.Request.Method == http.MethodHead:
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodedBody reads decoded content and closes both the decoder and the raw body
+type decodedBody struct {
+	io.Reader
+	raw io.ReadCloser
+}
+
+// Close closes the decoder, if closable, and the raw body
+func (d *decodedBody) Close() error {
+	if c, ok := d.Reader.(io.Closer); ok {
+		_ = c.Close()
+	}
+	return d.raw.Close()
+}
+
+// gzipBytes compresses data with gzip at the given level
+func gzipBytes(data []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
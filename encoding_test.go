@@ -0,0 +1,287 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_WithAutoDecompress_Gzip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("Expected Accept-Encoding to include gzip, got '%s'", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_ = json.NewEncoder(zw).Encode(map[string]string{"message": "compressed"})
+		_ = zw.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithAutoDecompress("gzip"))
+
+	var result map[string]string
+	if err := client.GET("/api/v1/test").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["message"] != "compressed" {
+		t.Errorf("Expected message 'compressed', got '%s'", result["message"])
+	}
+}
+
+func TestClient_WithAutoDecompress_RegisteredDecoder(t *testing.T) {
+	RegisterContentDecoder("x-base64", func(r io.Reader) (io.Reader, error) {
+		return base64.NewDecoder(base64.StdEncoding, r), nil
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "x-base64" {
+			t.Errorf("Expected Accept-Encoding 'x-base64', got '%s'", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "x-base64")
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(`{"message":"custom"}`))))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithAutoDecompress("x-base64", "zstd"))
+
+	var result map[string]string
+	if err := client.GET("/api/v1/test").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["message"] != "custom" {
+		t.Errorf("Expected message 'custom', got '%s'", result["message"])
+	}
+}
+
+func TestRequestBuilder_WithGzip(t *testing.T) {
+	payload := strings.Repeat(`{"name":"compressible"}`, 1000)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("Expected Content-Encoding gzip, got '%s'", r.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read gzip body: %v", err)
+			return
+		}
+		body, _ := io.ReadAll(zr)
+		if string(body) != payload {
+			t.Errorf("Decompressed body mismatch: got %d bytes", len(body))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	for _, level := range []int{gzip.BestSpeed, gzip.DefaultCompression, gzip.BestCompression, gzip.HuffmanOnly} {
+		err := client.POST("/api/v1/upload").
+			WithBody([]byte(payload)).
+			WithGzip(level).
+			Do(nil)
+		if err != nil {
+			t.Errorf("Level %d: request failed: %v", level, err)
+		}
+	}
+}
+
+func TestRequestBuilder_WithGzip_InvalidLevel(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	builder := client.POST("/api/v1/upload").WithBody([]byte("data")).WithGzip(42)
+	if builder.err == nil {
+		t.Fatal("Expected invalid level to set builder error")
+	}
+	if err := builder.Do(nil); err == nil {
+		t.Error("Expected Do to return the builder error")
+	}
+}
+
+func TestClient_DecompressesByDefault(t *testing.T) {
+	tests := []struct {
+		encoding string
+		compress func(io.Writer) io.WriteCloser
+	}{
+		{"gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }},
+		{"deflate", func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", tt.encoding)
+				zw := tt.compress(w)
+				_ = json.NewEncoder(zw).Encode(map[string]string{"message": "compressed"})
+				_ = zw.Close()
+			}))
+			defer server.Close()
+
+			client := NewClient(&Config{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			})
+
+			// An explicit Accept-Encoding stops the transport from decoding on its own
+			resp, err := client.GET("/api/v1/test").
+				WithHeader("Accept-Encoding", tt.encoding).
+				DoWithResponse()
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			if resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Content-Length") != "" {
+				t.Errorf("Expected encoding headers to be removed, got %v", resp.Header)
+			}
+
+			var result map[string]string
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if result["message"] != "compressed" {
+				t.Errorf("Expected message 'compressed', got '%s'", result["message"])
+			}
+		})
+	}
+}
+
+func TestClient_DecompressEmptyBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+	}{
+		{"no content", http.MethodGet, http.StatusNoContent},
+		{"not modified", http.MethodGet, http.StatusNotModified},
+		{"head", http.MethodHead, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Encoding", "gzip")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(&Config{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			})
+
+			builder := client.GET("/api/v1/test").WithHeader("Accept-Encoding", "gzip")
+			builder.method = tt.method
+			status, err := builder.DoStatus()
+			if err != nil && status != http.StatusNotModified {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if status != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, status)
+			}
+		})
+	}
+
+	t.Run("do", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		client := NewClient(&Config{
+			BaseURL: server.URL,
+			Timeout: 5 * time.Second,
+		})
+
+		if err := client.DELETE("/api/v1/test").WithHeader("Accept-Encoding", "gzip").Do(nil); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	})
+}
+
+func TestClient_WithAutoDecompress_KeepsDefaults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_ = json.NewEncoder(zw).Encode(map[string]string{"message": "compressed"})
+		_ = zw.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithAutoDecompress("br"))
+
+	var result map[string]string
+	if err := client.GET("/api/v1/test").WithHeader("Accept-Encoding", "gzip").Do(&result); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if result["message"] != "compressed" {
+		t.Errorf("Expected message 'compressed', got '%s'", result["message"])
+	}
+}
+
+func TestClient_WithCompression(t *testing.T) {
+	payload := strings.Repeat(`{"name":"large payload"}`, 10000)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.Header.Get("Content-Encoding") != "" {
+				t.Error("Expected no Content-Encoding for a request without body")
+			}
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("Expected Content-Encoding gzip, got '%s'", r.Header.Get("Content-Encoding"))
+		}
+		if r.ContentLength >= int64(len(payload)) {
+			t.Errorf("Expected compressed body smaller than %d bytes, got %d", len(payload), r.ContentLength)
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read gzip body: %v", err)
+			return
+		}
+		body, _ := io.ReadAll(zr)
+		if string(body) != payload {
+			t.Errorf("Decompressed body mismatch: got %d bytes", len(body))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithCompression(gzip.DefaultCompression))
+
+	if err := client.POST("/api/v1/upload").WithBody([]byte(payload)).Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if err := client.GET("/api/v1/test").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
@@ -1,22 +1,98 @@
 package httpclient
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// DefaultMaxErrorBodyBytes is the default cap on how much of an error response body is read
+const DefaultMaxErrorBodyBytes = 1 << 20
+
+// ErrRequestTooLarge is returned when a request body exceeds the limit set by WithMaxRequestBytes
+var ErrRequestTooLarge = errors.New("request body too large")
+
+// ErrBuilderConsumed is returned when a request builder is executed a second time.
+// Use RequestBuilder.Fork to reuse a preconfigured builder.
+var ErrBuilderConsumed = errors.New("request builder already executed")
+
+// ErrBodyReaderForked is returned when executing a builder forked from one whose
+// body was set with WithBodyReader without setting a body on the fork, as the
+// forks cannot share the reader
+var ErrBodyReaderForked = errors.New("request body reader cannot be shared by forked builders")
+
+// ErrCircuitOpen is returned without sending the request while the circuit
+// breaker set by WithCircuitBreaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrSkipRequest can be returned by a middleware to skip sending the request.
+// The call then succeeds with an empty 204 No Content response. Use SkipRequest
+// to serve a response of your own instead.
+var ErrSkipRequest = errors.New("request skipped by middleware")
+
 // APIError represents an HTTP API error
 type APIError struct {
 	StatusCode int
 	Message    string
+	Code       string
 	Body       []byte
+
+	// RequestID is the ID set via WithRequestID or WithRequestIDValue, if any
+	RequestID string
+
+	// Attempts lists the outcome of each attempt, such as "503" or "timeout",
+	// when the request was retried
+	Attempts []string
+
+	// Header holds the response headers
+	Header http.Header
 }
 
 // Error implements the error interface
 func (e *APIError) Error() string {
-	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+	msg := fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+	if len(e.Attempts) > 1 {
+		msg += fmt.Sprintf(" after %d attempts [%s]", len(e.Attempts), strings.Join(e.Attempts, ", "))
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request id %s)", e.RequestID)
+	}
+	return msg
+}
+
+// IsTimeout reports whether err comes from a request that timed out, through a
+// context deadline, the client Timeout or a network timeout
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsCanceled reports whether err comes from a request whose context was canceled
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// ResponseBody returns the raw error response body, which Message may only
+// quote in part. It is capped by WithMaxErrorBodyBytes.
+func (e *APIError) ResponseBody() []byte {
+	return e.Body
+}
+
+// RetryAfter returns the delay requested by the Retry-After response header,
+// given in seconds or as an HTTP date, typically on 429 and 503 responses
+func (e *APIError) RetryAfter() (time.Duration, bool) {
+	return parseRetryAfter(e.Header.Get("Retry-After"), time.Now())
 }
 
 // IsNotFound returns true if the error is a 404 Not Found
@@ -52,39 +128,153 @@ type ErrorResponse struct {
 	Code    string `json:"code,omitempty"`
 }
 
+// Default JSON error envelope field names, in lookup order
+var (
+	defaultErrorMessageFields = []string{"message", "detail", "error"}
+	defaultErrorCodeFields    = []string{"code"}
+)
+
 // handleErrorResponse processes error responses and returns structured errors
-func handleErrorResponse(resp *http.Response) error {
-	body, err := io.ReadAll(resp.Body)
+func (c *HTTPClient) handleErrorResponse(resp *http.Response) error {
+	body, err := c.readErrorBody(resp)
 	if err != nil {
-		return &APIError{
+		return c.annotateError(resp, &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    fmt.Sprintf("failed to read error response: %v", err),
+		})
+	}
+
+	if c.errorDecoder != nil {
+		// Hand the decoder a copy, leaving the original body for the caller to close
+		buffered := *resp
+		buffered.Body = io.NopCloser(bytes.NewReader(body))
+		if err := c.errorDecoder(&buffered); err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) {
+				c.annotateError(resp, apiErr)
+			}
+			return err
 		}
 	}
 
-	var errResp ErrorResponse
-	if err := json.Unmarshal(body, &errResp); err == nil {
-		if msg := firstNonEmpty(errResp.Message, errResp.Detail, errResp.Error); msg != "" {
+	return c.annotateError(resp, c.parseErrorResponse(resp.StatusCode, body))
+}
+
+// annotateError fills in the headers, request ID and retry attempts of the call, unless already set
+func (c *HTTPClient) annotateError(resp *http.Response, apiErr *APIError) *APIError {
+	if apiErr.Header == nil {
+		apiErr.Header = resp.Header
+	}
+	if resp.Request == nil {
+		return apiErr
+	}
+	ctx := resp.Request.Context()
+	if apiErr.RequestID == "" {
+		apiErr.RequestID, _ = RequestIDFromContext(ctx)
+	}
+	if apiErr.Attempts == nil {
+		apiErr.Attempts, _ = ctx.Value(retryOutcomesKey).([]string)
+	}
+	return apiErr
+}
+
+// readErrorBody reads an error response body up to the configured limit.
+// Compressed bodies were already decoded when the response was received.
+func (c *HTTPClient) readErrorBody(resp *http.Response) ([]byte, error) {
+	var reader io.Reader = resp.Body
+	limit := int64(c.maxErrorBodyBytes)
+	if limit == 0 {
+		limit = DefaultMaxErrorBodyBytes
+	}
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit)
+	}
+	return io.ReadAll(reader)
+}
+
+// parseErrorResponse builds an APIError from the response status and body
+func (c *HTTPClient) parseErrorResponse(statusCode int, body []byte) *APIError {
+	messageFields, codeFields := c.errorMessageFields, c.errorCodeFields
+	if messageFields == nil {
+		messageFields = defaultErrorMessageFields
+	}
+	if codeFields == nil {
+		codeFields = defaultErrorCodeFields
+	}
+
+	if envelope, ok := decodeErrorEnvelope(body); ok {
+		if msg := firstStringField(envelope, messageFields); msg != "" {
 			return &APIError{
-				StatusCode: resp.StatusCode,
+				StatusCode: statusCode,
 				Message:    msg,
+				Code:       firstCodeField(envelope, codeFields),
 				Body:       body,
 			}
 		}
 	}
 
 	return &APIError{
-		StatusCode: resp.StatusCode,
-		Message:    string(body),
+		StatusCode: statusCode,
+		Message:    messageSnippet(body),
 		Body:       body,
 	}
 }
 
-// firstNonEmpty returns the first non-empty string
-func firstNonEmpty(strs ...string) string {
-	for _, s := range strs {
-		if s != "" {
-			return s
+// maxErrorMessageLength caps the Message taken from an unrecognized error body
+const maxErrorMessageLength = 512
+
+// messageSnippet returns the start of body as an error message, so that large
+// error pages do not flood logs. The full body stays in APIError.Body.
+func messageSnippet(body []byte) string {
+	msg := string(body)
+	if len(msg) <= maxErrorMessageLength {
+		return msg
+	}
+	cut := maxErrorMessageLength
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut] + "..."
+}
+
+// decodeErrorEnvelope decodes a JSON object error body, keeping numbers as
+// json.Number so that numeric codes retain their original digits
+func decodeErrorEnvelope(body []byte) (map[string]interface{}, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var envelope map[string]interface{}
+	if err := dec.Decode(&envelope); err != nil || envelope == nil {
+		return nil, false
+	}
+	// Reject trailing data, as json.Unmarshal would
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, false
+	}
+	return envelope, true
+}
+
+// firstStringField returns the first non-empty string value among the given
+// fields. Other values, such as a nested error object or a bool, are skipped.
+func firstStringField(envelope map[string]interface{}, fields []string) string {
+	for _, field := range fields {
+		if v, ok := envelope[field].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// firstCodeField returns the first non-empty string or numeric value among
+// the given fields, with numbers formatted as they appear in the body
+func firstCodeField(envelope map[string]interface{}, fields []string) string {
+	for _, field := range fields {
+		switch v := envelope[field].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case json.Number:
+			return v.String()
 		}
 	}
 	return ""
@@ -99,7 +289,8 @@ func AuthMiddleware(authType, authValue string) Middleware {
 		case "APIKey":
 			req.Header.Set("X-API-Key", authValue)
 		case "Basic":
-			// For Basic auth, authValue should already be base64 encoded
+			// For Basic auth, authValue should already be base64 encoded;
+			// use BasicAuthMiddleware for raw credentials
 			req.Header.Set("Authorization", "Basic "+authValue)
 		default:
 			return fmt.Errorf("unsupported auth type: %s", authType)
@@ -108,6 +299,30 @@ func AuthMiddleware(authType, authValue string) Middleware {
 	}
 }
 
+// TokenMiddleware creates a middleware that fetches a Bearer token from provider
+// before each request, such as an OAuth2 access token that is refreshed when it
+// expires. A provider error aborts the request. The provider is called with the
+// request context and should cache tokens itself.
+func TokenMiddleware(provider func(context.Context) (string, error)) Middleware {
+	return func(req *http.Request) error {
+		token, err := provider(req.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// BasicAuthMiddleware creates a middleware that adds HTTP Basic authentication
+// from a raw username and password, encoding them as required
+func BasicAuthMiddleware(username, password string) Middleware {
+	return func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
 // HeaderMiddleware creates a middleware that adds custom headers
 func HeaderMiddleware(headers map[string]string) Middleware {
 	return func(req *http.Request) error {
@@ -0,0 +1,430 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_WithErrorFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"msg":"invalid name","errorCode":"E_NAME"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithErrorFields([]string{"msg"}, []string{"errorCode"}))
+
+	err := client.GET("/api/v1/test").Do(nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.Message != "invalid name" {
+		t.Errorf("Expected message 'invalid name', got '%s'", apiErr.Message)
+	}
+	if apiErr.Code != "E_NAME" {
+		t.Errorf("Expected code 'E_NAME', got '%s'", apiErr.Code)
+	}
+}
+
+func TestParseErrorResponse_NonStringFields(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}).(*HTTPClient)
+
+	tests := []struct {
+		name    string
+		body    string
+		message string
+		code    string
+	}{
+		{
+			name:    "nested error object",
+			body:    `{"error":{"code":404,"message":"user not found"}}`,
+			message: `{"error":{"code":404,"message":"user not found"}}`,
+		},
+		{
+			name:    "bool error field",
+			body:    `{"error":true}`,
+			message: `{"error":true}`,
+		},
+		{
+			name:    "large numeric code",
+			body:    `{"message":"quota exceeded","code":2000000}`,
+			message: "quota exceeded",
+			code:    "2000000",
+		},
+		{
+			name:    "string code",
+			body:    `{"error":"bad input","code":"E_INPUT"}`,
+			message: "bad input",
+			code:    "E_INPUT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := client.parseErrorResponse(http.StatusBadRequest, []byte(tt.body))
+			if apiErr.Message != tt.message {
+				t.Errorf("Expected message '%s', got '%s'", tt.message, apiErr.Message)
+			}
+			if apiErr.Code != tt.code {
+				t.Errorf("Expected code '%s', got '%s'", tt.code, apiErr.Code)
+			}
+		})
+	}
+}
+
+func TestClient_GzipErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusInternalServerError)
+		zw := gzip.NewWriter(w)
+		_, _ = zw.Write([]byte(`{"message":"database unavailable"}`))
+		_ = zw.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.GET("/api/v1/test").
+		WithHeader("Accept-Encoding", "gzip").
+		Do(nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.Message != "database unavailable" {
+		t.Errorf("Expected message 'database unavailable', got '%s'", apiErr.Message)
+	}
+}
+
+func TestClient_WithRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(RequestIDHeader)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithResponseMiddleware(DebugResponseMiddleware(&DebugOptions{Writer: &buf})))
+
+	err := client.GET("/api/v1/test").WithRequestID().Do(nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if received == "" {
+		t.Fatal("Expected request ID header to be sent")
+	}
+	if apiErr.RequestID != received {
+		t.Errorf("Expected error request ID '%s', got '%s'", received, apiErr.RequestID)
+	}
+	if !strings.Contains(buf.String(), "Request ID: "+received) {
+		t.Errorf("Debug output missing request ID, got: %s", buf.String())
+	}
+}
+
+func TestClient_WithRequestIDValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(RequestIDHeader); got != "req-123" {
+			t.Errorf("Expected request ID 'req-123', got '%s'", got)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.GET("/api/v1/test").WithRequestIDValue("req-123").Do(nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.RequestID != "req-123" {
+		t.Errorf("Expected error request ID 'req-123', got '%s'", apiErr.RequestID)
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Trace"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(RequestIDMiddleware("X-Trace", func() string { return "generated" })))
+
+	err := client.GET("/api/v1/test").Do(nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.RequestID != "generated" {
+		t.Errorf("Expected error request ID 'generated', got '%s'", apiErr.RequestID)
+	}
+
+	_ = client.GET("/api/v1/test").WithHeader("X-Trace", "preset").Do(nil)
+
+	if len(received) != 2 || received[0] != "generated" || received[1] != "preset" {
+		t.Errorf("Expected IDs [generated preset], got %v", received)
+	}
+}
+
+func TestRequestIDMiddleware_Defaults(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(RequestIDHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(RequestIDMiddleware("", nil)))
+
+	if err := client.GET("/api/v1/test").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if len(received) != 36 || strings.Count(received, "-") != 4 {
+		t.Errorf("Expected a UUID request ID, got '%s'", received)
+	}
+}
+
+func TestClient_HTMLErrorPage(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("<p>Internal Server Error</p>", 100) + "</body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.GET("/api/v1/test").Do(nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if string(apiErr.ResponseBody()) != page {
+		t.Errorf("Expected full page in body, got %d bytes", len(apiErr.ResponseBody()))
+	}
+	if len(apiErr.Message) != 515 || !strings.HasPrefix(page, strings.TrimSuffix(apiErr.Message, "...")) {
+		t.Errorf("Expected 512-byte message snippet, got %d bytes: %s", len(apiErr.Message), apiErr.Message)
+	}
+}
+
+func TestClient_WithErrorDecoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		if r.URL.Path == "/custom" {
+			_, _ = w.Write([]byte(`{"errors":[{"detail":"name is required","code":"missing_field"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"message":"standard error"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithErrorDecoder(func(resp *http.Response) error {
+		var envelope struct {
+			Errors []struct {
+				Detail string `json:"detail"`
+				Code   string `json:"code"`
+			} `json:"errors"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || len(envelope.Errors) == 0 {
+			return nil
+		}
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    envelope.Errors[0].Detail,
+			Code:       envelope.Errors[0].Code,
+		}
+	}))
+
+	err := client.GET("/custom").WithRequestIDValue("req-1").Do(nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.Message != "name is required" || apiErr.Code != "missing_field" {
+		t.Errorf("Expected decoded message and code, got '%s' and '%s'", apiErr.Message, apiErr.Code)
+	}
+	if apiErr.RequestID != "req-1" {
+		t.Errorf("Expected request ID 'req-1', got '%s'", apiErr.RequestID)
+	}
+
+	// Falls back to the built-in parsing when the decoder returns nil
+	err = client.GET("/standard").Do(nil)
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.Message != "standard error" {
+		t.Errorf("Expected message 'standard error', got '%s'", apiErr.Message)
+	}
+}
+
+func TestIsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 50 * time.Millisecond,
+	})
+
+	err := client.GET("/slow").Do(nil)
+	if !IsTimeout(err) {
+		t.Errorf("Expected client timeout to be classified as timeout, got %v", err)
+	}
+	if IsCanceled(err) {
+		t.Errorf("Expected client timeout not to be classified as canceled, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = NewClient(&Config{BaseURL: server.URL}).GET("/slow").WithContext(ctx).Do(nil)
+	if !IsTimeout(err) {
+		t.Errorf("Expected context deadline to be classified as timeout, got %v", err)
+	}
+}
+
+func TestIsCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	err := client.GET("/slow").WithContext(ctx).Do(nil)
+	if !IsCanceled(err) {
+		t.Errorf("Expected cancellation to be classified as canceled, got %v", err)
+	}
+	if IsTimeout(err) {
+		t.Errorf("Expected cancellation not to be classified as timeout, got %v", err)
+	}
+}
+
+func TestAPIError_RetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.GET("/api/v1/test").Do(nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if got := apiErr.Header.Get("Retry-After"); got != "120" {
+		t.Errorf("Expected Retry-After header '120', got '%s'", got)
+	}
+	if d, ok := apiErr.RetryAfter(); !ok || d != 2*time.Minute {
+		t.Errorf("Expected RetryAfter of 2m, got %v (ok=%v)", d, ok)
+	}
+
+	if _, ok := (&APIError{StatusCode: http.StatusTooManyRequests}).RetryAfter(); ok {
+		t.Error("Expected no RetryAfter without a header")
+	}
+}
+
+func TestClient_ErrorBodyLimit(t *testing.T) {
+	chunk := bytes.Repeat([]byte("x"), 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		for i := 0; i < 64; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected int
+	}{
+		{"default", nil, DefaultMaxErrorBodyBytes},
+		{"custom", []Option{WithMaxErrorBodyBytes(1024)}, 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(&Config{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			}, tt.opts...)
+
+			err := client.GET("/huge").Do(nil)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected APIError, got %v", err)
+			}
+			if len(apiErr.Body) != tt.expected {
+				t.Errorf("Expected body of %d bytes, got %d", tt.expected, len(apiErr.Body))
+			}
+			if apiErr.StatusCode != http.StatusBadGateway {
+				t.Errorf("Expected status 502, got %d", apiErr.StatusCode)
+			}
+		})
+	}
+}
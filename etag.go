@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ETagStore remembers the ETag and body of GET responses by URL for conditional
+// requests. It is safe for concurrent use and grows with the number of URLs.
+type ETagStore struct {
+	mu      sync.RWMutex
+	entries map[string]etagEntry
+}
+
+// etagEntry is a cached response
+type etagEntry struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// NewETagStore creates an empty ETagStore
+func NewETagStore() *ETagStore {
+	return &ETagStore{entries: make(map[string]etagEntry)}
+}
+
+// get returns the entry cached for url, if any
+func (s *ETagStore) get(url string) (etagEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[url]
+	return entry, ok
+}
+
+// put caches an entry for url
+func (s *ETagStore) put(url string, entry etagEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[url] = entry
+}
+
+// ETagMiddleware returns a middleware that sends If-None-Match with the ETag
+// stored for the URL of a GET request, unless the request sets it already.
+// Pair it with ETagResponseMiddleware using the same store.
+func ETagMiddleware(store *ETagStore) Middleware {
+	return func(req *http.Request) error {
+		if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+			return nil
+		}
+		if entry, ok := store.get(req.URL.String()); ok {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		return nil
+	}
+}
+
+// ETagResponseMiddleware returns a response middleware that stores the ETag and
+// body of successful GET responses, and turns a 304 Not Modified for a stored
+// URL into a 200 OK carrying the stored body, so Do decodes it as usual.
+func ETagResponseMiddleware(store *ETagStore) ResponseMiddleware {
+	return func(resp *http.Response) error {
+		req := resp.Request
+		if req == nil || req.Method != http.MethodGet {
+			return nil
+		}
+		url := req.URL.String()
+
+		switch {
+		case resp.StatusCode == http.StatusNotModified:
+			entry, ok := store.get(url)
+			if !ok {
+				return nil
+			}
+			resp.StatusCode = http.StatusOK
+			resp.Status = "200 OK"
+			for k, v := range entry.header {
+				if resp.Header.Get(k) == "" {
+					resp.Header[k] = v
+				}
+			}
+			resp.ContentLength = int64(len(entry.body))
+			resp.Body = io.NopCloser(bytes.NewReader(entry.body))
+		case resp.StatusCode == http.StatusOK:
+			etag := resp.Header.Get("ETag")
+			if etag == "" {
+				return nil
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			store.put(url, etagEntry{etag: etag, header: resp.Header.Clone(), body: body})
+		}
+		return nil
+	}
+}
+
+// WithETagCache makes GET requests conditional using store, with ETagMiddleware
+// and ETagResponseMiddleware. A nil store creates one for the client.
+//
+// Example usage:
+//
+//	client := httpclient.NewClient(config, httpclient.WithETagCache(nil))
+func WithETagCache(store *ETagStore) Option {
+	if store == nil {
+		store = NewETagStore()
+	}
+	return func(c *HTTPClient) {
+		c.middleware = append(c.middleware, ETagMiddleware(store))
+		c.responseMiddleware = append(c.responseMiddleware, ETagResponseMiddleware(store))
+	}
+}
@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithETagCache(t *testing.T) {
+	var notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"name":"alice"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithETagCache(nil))
+
+	for i := 0; i < 2; i++ {
+		var user map[string]string
+		if err := client.GET("/users/1").Do(&user); err != nil {
+			t.Fatalf("Request %d failed: %v", i+1, err)
+		}
+		if user["name"] != "alice" {
+			t.Errorf("Request %d: expected name 'alice', got '%s'", i+1, user["name"])
+		}
+	}
+
+	if got := atomic.LoadInt32(&notModified); got != 1 {
+		t.Errorf("Expected 1 conditional request answered with 304, got %d", got)
+	}
+}
+
+func TestETagMiddleware_KeepsExplicitHeader(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	store := NewETagStore()
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(ETagMiddleware(store)),
+		WithResponseMiddleware(ETagResponseMiddleware(store)))
+
+	_, _ = client.GET("/data").DoStatus()
+	_, _ = client.GET("/data").WithHeader("If-None-Match", `"v0"`).DoStatus()
+	_, _ = client.GET("/other").DoStatus()
+
+	if len(received) != 3 || received[0] != "" || received[1] != `"v0"` || received[2] != "" {
+		t.Errorf("Unexpected If-None-Match headers: %q", received)
+	}
+}
@@ -3,6 +3,7 @@ package httpclient_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	httpclient "github.com/futuretea/go-http-client"
@@ -58,7 +59,7 @@ func Example_debugMiddleware_noColor() {
 
 // Example_debugMiddleware_toFile demonstrates writing debug output to a file
 func Example_debugMiddleware_toFile() {
-	logFile, err := os.Create("http-debug.log")
+	logFile, err := os.Create(filepath.Join(os.TempDir(), "http-debug.log"))
 	if err != nil {
 		fmt.Printf("Failed to create log file: %v\n", err)
 		return
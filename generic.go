@@ -0,0 +1,24 @@
+package httpclient
+
+// DoJSON executes the request and decodes the response into a new value of type T
+//
+// Example usage:
+//
+//	user, err := httpclient.DoJSON[User](client.GET("/api/v1/users/123"))
+func DoJSON[T any](b *RequestBuilder) (T, error) {
+	var result T
+	if err := b.Do(&result); err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// DoJSONMap executes the request and decodes a JSON object response into a map of T
+//
+// Example usage:
+//
+//	users, err := httpclient.DoJSONMap[User](client.GET("/api/v1/users"))
+func DoJSONMap[T any](b *RequestBuilder) (map[string]T, error) {
+	return DoJSON[map[string]T](b)
+}
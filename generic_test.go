@@ -0,0 +1,37 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDoJSONMap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"alice":{"id":"1","name":"Alice"},"bob":{"id":"2","name":"Bob"}}`))
+	}))
+	defer server.Close()
+
+	type User struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	users, err := DoJSONMap[User](client.GET("/api/v1/users"))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("Expected 2 users, got %d", len(users))
+	}
+	if users["alice"].Name != "Alice" || users["bob"].ID != "2" {
+		t.Errorf("Users not decoded correctly, got: %+v", users)
+	}
+}
@@ -0,0 +1,258 @@
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"unicode/utf8"
+
+	httpclient "github.com/futuretea/go-http-client"
+)
+
+// redactedValue replaces the values of redacted headers in a cassette
+const redactedValue = "REDACTED"
+
+// Cassette is a recorded sequence of HTTP interactions, stored as JSON
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and the response it received
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is a recorded request
+type CassetteRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	CassetteBody
+}
+
+// CassetteResponse is a recorded response
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	CassetteBody
+}
+
+// CassetteBody holds a recorded body as text, or base64 encoded when it is not
+// valid UTF-8, such as a compressed body
+type CassetteBody struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"body_base64,omitempty"`
+}
+
+// newCassetteBody records body
+func newCassetteBody(body []byte) CassetteBody {
+	if utf8.Valid(body) {
+		return CassetteBody{Body: string(body)}
+	}
+	return CassetteBody{BodyBase64: base64.StdEncoding.EncodeToString(body)}
+}
+
+// Bytes returns the recorded body
+func (b CassetteBody) Bytes() ([]byte, error) {
+	if b.BodyBase64 == "" {
+		return []byte(b.Body), nil
+	}
+	return base64.StdEncoding.DecodeString(b.BodyBase64)
+}
+
+// LoadCassette reads a cassette from the JSON file at path
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette: %w", err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette as indented JSON to the file at path
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// RecordingTransport is an httpclient.Doer that sends requests through another
+// Doer and records every interaction, for use with httpclient.WithHTTPClient.
+// Call Save once done to write the cassette for a ReplayTransport. It is safe
+// for concurrent use.
+//
+// Example usage:
+//
+//	rec := httpclienttest.NewRecordingTransport(http.DefaultClient, "Authorization")
+//	client := httpclient.NewClient(config, httpclient.WithHTTPClient(rec))
+//	// ...
+//	err := rec.Save("testdata/users.json")
+type RecordingTransport struct {
+	next   httpclient.Doer
+	redact []string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecordingTransport creates a RecordingTransport sending requests with next,
+// or http.DefaultClient if nil. The values of the headers named in redact, such
+// as Authorization, are replaced in the cassette, in requests and responses.
+func NewRecordingTransport(next httpclient.Doer, redact ...string) *RecordingTransport {
+	if next == nil {
+		next = http.DefaultClient
+	}
+	return &RecordingTransport{next: next, redact: redact}
+}
+
+// Do sends req and records it along with its response. Both bodies are read in
+// full; the response body is restored for the caller.
+func (r *RecordingTransport) Do(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("httpclienttest: failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("httpclienttest: failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: CassetteRequest{
+			Method:       req.Method,
+			URL:          req.URL.String(),
+			Header:       redactHeaders(req.Header, r.redact),
+			CassetteBody: newCassetteBody(reqBody),
+		},
+		Response: CassetteResponse{
+			StatusCode:   resp.StatusCode,
+			Header:       redactHeaders(resp.Header, r.redact),
+			CassetteBody: newCassetteBody(respBody),
+		},
+	})
+	return resp, nil
+}
+
+// Cassette returns a copy of the interactions recorded so far
+func (r *RecordingTransport) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Save writes the interactions recorded so far to the file at path
+func (r *RecordingTransport) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// redactHeaders returns a copy of header with the values of the named headers replaced
+func redactHeaders(header http.Header, redact []string) http.Header {
+	redacted := header.Clone()
+	for _, name := range redact {
+		if _, ok := redacted[http.CanonicalHeaderKey(name)]; ok {
+			redacted.Set(name, redactedValue)
+		}
+	}
+	return redacted
+}
+
+// ReplayTransport is an httpclient.Doer that answers requests from a cassette
+// without a network, for use with httpclient.WithHTTPClient. A request is
+// matched by method, URL and body to the first recorded interaction it has not
+// replayed yet; headers are ignored. A request without a match fails with an
+// error. It is safe for concurrent use.
+//
+// Example usage:
+//
+//	replay, err := httpclienttest.NewReplayTransport("testdata/users.json")
+//	// ...
+//	client := httpclient.NewClient(config, httpclient.WithHTTPClient(replay))
+type ReplayTransport struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	replayed []bool
+}
+
+// NewReplayTransport creates a ReplayTransport from the cassette file at path
+func NewReplayTransport(path string) (*ReplayTransport, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayTransportFromCassette(c), nil
+}
+
+// NewReplayTransportFromCassette creates a ReplayTransport answering from c
+func NewReplayTransportFromCassette(c *Cassette) *ReplayTransport {
+	return &ReplayTransport{cassette: c, replayed: make([]bool, len(c.Interactions))}
+}
+
+// Do returns the recorded response for req
+func (r *ReplayTransport) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("httpclienttest: failed to read request body: %w", err)
+		}
+	}
+	url := req.URL.String()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		recorded := interaction.Request
+		if r.replayed[i] || recorded.Method != req.Method || recorded.URL != url {
+			continue
+		}
+		recordedBody, err := recorded.Bytes()
+		if err != nil || !bytes.Equal(recordedBody, body) {
+			continue
+		}
+
+		respBody, err := interaction.Response.Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("httpclienttest: failed to decode recorded response body: %w", err)
+		}
+		r.replayed[i] = true
+		return newHTTPResponse(req, MockResponse{
+			StatusCode: interaction.Response.StatusCode,
+			Header:     interaction.Response.Header,
+			Body:       respBody,
+		}), nil
+	}
+	return nil, fmt.Errorf("httpclienttest: no recorded interaction for %s %s", req.Method, url)
+}
@@ -0,0 +1,113 @@
+package httpclienttest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	httpclient "github.com/futuretea/go-http-client"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret-session")
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"2"}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"1","name":"alice"}`))
+		}
+	}))
+
+	cassette := filepath.Join(t.TempDir(), "users.json")
+	config := &httpclient.Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}
+
+	// Record pass against the real server
+	rec := NewRecordingTransport(nil, "Authorization", "Set-Cookie")
+	client := httpclient.NewClient(config,
+		httpclient.WithHTTPClient(rec),
+		httpclient.WithMiddleware(httpclient.AuthMiddleware("Bearer", "secret-token")))
+
+	var user map[string]string
+	if err := client.GET("/users/1").Do(&user); err != nil {
+		t.Fatalf("Recorded request failed: %v", err)
+	}
+	if user["name"] != "alice" {
+		t.Errorf("Expected name 'alice' while recording, got '%s'", user["name"])
+	}
+	var created map[string]string
+	if err := client.POST("/users").WithJSON(map[string]string{"name": "bob"}).Do(&created); err != nil {
+		t.Fatalf("Recorded request failed: %v", err)
+	}
+	if err := rec.Save(cassette); err != nil {
+		t.Fatalf("Failed to save cassette: %v", err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatalf("Failed to read cassette: %v", err)
+	}
+	if strings.Contains(string(data), "secret-token") || strings.Contains(string(data), "secret-session") {
+		t.Errorf("Expected secrets to be redacted, got cassette:\n%s", data)
+	}
+	if !strings.Contains(string(data), redactedValue) {
+		t.Errorf("Expected redacted headers in cassette, got:\n%s", data)
+	}
+
+	// Replay pass without the server
+	replay, err := NewReplayTransport(cassette)
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	client = httpclient.NewClient(config, httpclient.WithHTTPClient(replay))
+
+	user = nil
+	if err := client.GET("/users/1").Do(&user); err != nil {
+		t.Fatalf("Replayed request failed: %v", err)
+	}
+	if user["name"] != "alice" {
+		t.Errorf("Expected name 'alice' on replay, got '%s'", user["name"])
+	}
+
+	resp, err := client.POST("/users").WithJSON(map[string]string{"name": "bob"}).DoResponse(&created)
+	if err != nil {
+		t.Fatalf("Replayed request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated || created["id"] != "2" {
+		t.Errorf("Expected 201 with id '2' on replay, got %d with %v", resp.StatusCode, created)
+	}
+
+	// A request with a different body was not recorded
+	if err := client.POST("/users").WithJSON(map[string]string{"name": "carol"}).Do(nil); err == nil {
+		t.Error("Expected error for a request missing from the cassette")
+	}
+	// Each interaction is replayed once
+	if err := client.GET("/users/1").Do(nil); err == nil {
+		t.Error("Expected error for an interaction replayed twice")
+	}
+}
+
+func TestCassetteBody_Binary(t *testing.T) {
+	body := []byte{0x1f, 0x8b, 0xff, 0x00}
+	recorded := newCassetteBody(body)
+	if recorded.Body != "" || recorded.BodyBase64 == "" {
+		t.Fatalf("Expected binary body to be base64 encoded, got %+v", recorded)
+	}
+	decoded, err := recorded.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if string(decoded) != string(body) {
+		t.Errorf("Expected body %v, got %v", body, decoded)
+	}
+}
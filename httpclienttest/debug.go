@@ -0,0 +1,180 @@
+// Package httpclienttest provides helpers for testing code built on httpclient.
+package httpclienttest
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugEntry is a request/response exchange parsed from debug middleware output
+type DebugEntry struct {
+	// Request side, from DebugMiddleware
+	Method         string
+	Path           string
+	Proto          string
+	RequestHeaders http.Header
+	RequestBody    string
+
+	// Response side, from DebugResponseMiddleware
+	RequestID       string
+	Elapsed         time.Duration
+	Status          string
+	StatusCode      int
+	ResponseHeaders http.Header
+	ResponseBody    string
+
+	// Truncated reports whether a printed body was cut at MaxBodyBytes
+	Truncated bool
+
+	// Error is the transport error printed by httpclient.WithDebugOnError
+	Error string
+}
+
+// ansiPattern matches ANSI color escape sequences
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// parser section states
+const (
+	sectionNone = iota
+	sectionRequestHeaders
+	sectionRequestBody
+	sectionResponseHeaders
+	sectionResponseBody
+)
+
+// ParseDebug parses the curl-style output of httpclient.DebugMiddleware and
+// httpclient.DebugResponseMiddleware into structured entries, in output order.
+// A response is attached to the preceding request when both were written to
+// the same writer. Color codes are stripped.
+func ParseDebug(r io.Reader) []DebugEntry {
+	var (
+		entries   []*DebugEntry
+		current   *DebugEntry
+		section   = sectionNone
+		body      []string
+		requestID string
+		elapsed   time.Duration
+	)
+
+	flushBody := func() {
+		if current == nil {
+			body = nil
+			return
+		}
+		text := strings.TrimSuffix(strings.Join(body, "\n"), "\n")
+		switch section {
+		case sectionRequestBody:
+			current.RequestBody = text
+		case sectionResponseBody:
+			current.ResponseBody = text
+		}
+		body = nil
+	}
+
+	newEntry := func() *DebugEntry {
+		entry := &DebugEntry{
+			RequestHeaders:  http.Header{},
+			ResponseHeaders: http.Header{},
+		}
+		entries = append(entries, entry)
+		return entry
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := ansiPattern.ReplaceAllString(scanner.Text(), "")
+
+		switch {
+		case line == ">":
+			section = sectionRequestBody
+		case line == "<":
+			section = sectionResponseBody
+		case strings.HasPrefix(line, "> "):
+			if section == sectionRequestHeaders {
+				addHeader(current.RequestHeaders, line[2:])
+				continue
+			}
+			flushBody()
+			current = newEntry()
+			current.Method, current.Path, current.Proto = parseRequestLine(line[2:])
+			section = sectionRequestHeaders
+		case strings.HasPrefix(line, "< "):
+			if section == sectionResponseHeaders {
+				addHeader(current.ResponseHeaders, line[2:])
+				continue
+			}
+			flushBody()
+			// Attach the response to the open request unless it already has one
+			if current == nil || current.Status != "" {
+				current = newEntry()
+			}
+			current.RequestID, requestID = requestID, ""
+			current.Elapsed, elapsed = elapsed, 0
+			current.Status, current.StatusCode = parseStatusLine(line[2:])
+			section = sectionResponseHeaders
+		case strings.HasPrefix(line, "* Request ID: "):
+			flushBody()
+			section = sectionNone
+			requestID = strings.TrimPrefix(line, "* Request ID: ")
+		case strings.HasPrefix(line, "* Time: "):
+			flushBody()
+			section = sectionNone
+			elapsed, _ = time.ParseDuration(strings.TrimPrefix(line, "* Time: "))
+		case strings.HasPrefix(line, "* Error: "):
+			flushBody()
+			section = sectionNone
+			if current != nil {
+				current.Error = strings.TrimPrefix(line, "* Error: ")
+			}
+		case strings.HasPrefix(line, "... (body truncated"):
+			if current != nil {
+				current.Truncated = true
+			}
+		case section == sectionRequestBody || section == sectionResponseBody:
+			body = append(body, line)
+		}
+	}
+	flushBody()
+
+	result := make([]DebugEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = *entry
+	}
+	return result
+}
+
+// parseRequestLine splits "METHOD /path PROTO"
+func parseRequestLine(line string) (method, path, proto string) {
+	parts := strings.SplitN(line, " ", 3)
+	switch len(parts) {
+	case 3:
+		return parts[0], parts[1], parts[2]
+	case 2:
+		return parts[0], parts[1], ""
+	default:
+		return line, "", ""
+	}
+}
+
+// parseStatusLine splits "PROTO 200 OK" into the status text and code
+func parseStatusLine(line string) (string, int) {
+	_, status, _ := strings.Cut(line, " ")
+	codeText, _, _ := strings.Cut(status, " ")
+	code, _ := strconv.Atoi(codeText)
+	return status, code
+}
+
+// addHeader parses "Key: value" into headers
+func addHeader(headers http.Header, line string) {
+	key, value, ok := strings.Cut(line, ": ")
+	if !ok {
+		return
+	}
+	headers.Add(key, value)
+}
@@ -0,0 +1,126 @@
+package httpclienttest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpclient "github.com/futuretea/go-http-client"
+)
+
+func TestParseDebug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"123"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	opts := &httpclient.DebugOptions{Color: true, Writer: &buf, ShowBody: true}
+	client := httpclient.NewClient(&httpclient.Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	},
+		httpclient.WithMiddleware(httpclient.DebugMiddleware(opts)),
+		httpclient.WithResponseMiddleware(httpclient.DebugResponseMiddleware(opts)),
+	)
+
+	for _, name := range []string{"alice", "bob"} {
+		err := client.POST("/api/users").
+			WithJSON(map[string]string{"name": name}).
+			WithRequestIDValue("req-" + name).
+			Do(nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+
+	entries := ParseDebug(&buf)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d: %+v", len(entries), entries)
+	}
+
+	entry := entries[1]
+	if entry.Method != http.MethodPost || entry.Path != "/api/users" || entry.Proto != "HTTP/1.1" {
+		t.Errorf("Unexpected request line: %s %s %s", entry.Method, entry.Path, entry.Proto)
+	}
+	if got := entry.RequestHeaders.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected request Content-Type application/json, got '%s'", got)
+	}
+	if entry.RequestBody != `{"name":"bob"}` {
+		t.Errorf("Unexpected request body: %q", entry.RequestBody)
+	}
+	if entry.RequestID != "req-bob" {
+		t.Errorf("Expected request ID 'req-bob', got '%s'", entry.RequestID)
+	}
+	if entry.StatusCode != http.StatusCreated || entry.Status != "201 Created" {
+		t.Errorf("Unexpected status: %d %s", entry.StatusCode, entry.Status)
+	}
+	if got := entry.ResponseHeaders.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected response Content-Type application/json, got '%s'", got)
+	}
+	if entry.ResponseBody != `{"id":"123"}` {
+		t.Errorf("Unexpected response body: %q", entry.ResponseBody)
+	}
+}
+
+func TestParseDebug_WithoutRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	opts := &httpclient.DebugOptions{Writer: &buf, ShowBody: true}
+	client := httpclient.NewClient(&httpclient.Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	},
+		httpclient.WithMiddleware(httpclient.DebugMiddleware(opts)),
+		httpclient.WithResponseMiddleware(httpclient.DebugResponseMiddleware(opts)),
+	)
+
+	if err := client.POST("/api/users").WithBody([]byte("hello")).Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	entries := ParseDebug(&buf)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].RequestBody != "hello" {
+		t.Errorf("Unexpected request body: %q", entries[0].RequestBody)
+	}
+	if entries[0].Elapsed <= 0 {
+		t.Errorf("Expected elapsed time to be parsed, got %v", entries[0].Elapsed)
+	}
+	if entries[0].StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", entries[0].StatusCode)
+	}
+}
+
+func TestParseDebug_Error(t *testing.T) {
+	var buf bytes.Buffer
+	client := httpclient.NewClient(&httpclient.Config{
+		BaseURL: "http://127.0.0.1:1",
+		Timeout: 5 * time.Second,
+	}, httpclient.WithDebugOnError(&httpclient.DebugOptions{Writer: &buf, ShowBody: true}))
+
+	if err := client.POST("/api/users").WithBody([]byte("hello")).Do(nil); err == nil {
+		t.Fatal("Expected transport error")
+	}
+
+	entries := ParseDebug(&buf)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].RequestBody != "hello" {
+		t.Errorf("Unexpected request body: %q", entries[0].RequestBody)
+	}
+	if entries[0].Error == "" {
+		t.Error("Expected transport error to be parsed")
+	}
+}
@@ -0,0 +1,211 @@
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// MockResponse is a canned response served by MockDoer
+type MockResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// Err, if set, is returned instead of a response, like a transport error
+	Err error
+}
+
+// JSONResponse returns a MockResponse with v encoded as JSON and a
+// Content-Type of application/json. It panics if v cannot be encoded.
+func JSONResponse(statusCode int, v interface{}) MockResponse {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("httpclienttest: failed to encode JSON response: %v", err))
+	}
+	return MockResponse{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       body,
+	}
+}
+
+// RecordedRequest is a request received by MockDoer
+type RecordedRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// mockRoute is a response registered for a method and path
+type mockRoute struct {
+	method string
+	path   string
+	resp   MockResponse
+}
+
+// MockDoer is an httpclient.Doer that serves canned responses without a
+// network, for unit tests through httpclient.WithHTTPClient. Responses
+// registered with On are matched by method and path first; other requests
+// are served from the queue filled by Enqueue, in order. Every request is
+// recorded for assertions. It is safe for concurrent use.
+//
+// Example usage:
+//
+//	mock := httpclienttest.NewMockDoer()
+//	mock.On(http.MethodGet, "/api/v1/users/1", httpclienttest.JSONResponse(200, user))
+//	client := httpclient.NewClient(config, httpclient.WithHTTPClient(mock))
+//	// ...
+//	mock.AssertCalled(t, http.MethodGet, "/api/v1/users/1")
+type MockDoer struct {
+	mu       sync.Mutex
+	routes   []mockRoute
+	queue    []MockResponse
+	requests []RecordedRequest
+}
+
+// NewMockDoer creates a MockDoer without responses
+func NewMockDoer() *MockDoer {
+	return &MockDoer{}
+}
+
+// On serves resp to every request with the given method and URL path. An empty
+// method matches any method. When several routes match, the first one added wins.
+func (m *MockDoer) On(method, path string, resp MockResponse) *MockDoer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = append(m.routes, mockRoute{method: method, path: path, resp: resp})
+	return m
+}
+
+// Enqueue adds responses served once each, in order, to requests matching no route
+func (m *MockDoer) Enqueue(resps ...MockResponse) *MockDoer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queue = append(m.queue, resps...)
+	return m
+}
+
+// Do records req and serves the matching canned response. A request matching
+// neither a route nor a queued response fails with an error.
+func (m *MockDoer) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("httpclienttest: failed to read request body: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	m.requests = append(m.requests, RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL,
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+	resp, ok := m.match(req)
+	m.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("httpclienttest: no mock response for %s %s", req.Method, req.URL.Path)
+	}
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return newHTTPResponse(req, resp), nil
+}
+
+// match returns the response for req from the routes or the queue; m.mu must be held
+func (m *MockDoer) match(req *http.Request) (MockResponse, bool) {
+	for _, route := range m.routes {
+		if (route.method == "" || route.method == req.Method) && route.path == req.URL.Path {
+			return route.resp, true
+		}
+	}
+	if len(m.queue) == 0 {
+		return MockResponse{}, false
+	}
+	resp := m.queue[0]
+	m.queue = m.queue[1:]
+	return resp, true
+}
+
+// newHTTPResponse builds the *http.Response for a canned response
+func newHTTPResponse(req *http.Request, resp MockResponse) *http.Response {
+	statusCode := resp.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	header := resp.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// Requests returns the requests received so far, in order
+func (m *MockDoer) Requests() []RecordedRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RecordedRequest(nil), m.requests...)
+}
+
+// Calls returns the requests received so far with the given method and URL path.
+// An empty method matches any method.
+func (m *MockDoer) Calls(method, path string) []RecordedRequest {
+	var calls []RecordedRequest
+	for _, req := range m.Requests() {
+		if (method == "" || req.Method == method) && req.URL.Path == path {
+			calls = append(calls, req)
+		}
+	}
+	return calls
+}
+
+// AssertCalled fails the test unless a request with the given method and URL
+// path was received, returning the last such request
+func (m *MockDoer) AssertCalled(t testing.TB, method, path string) RecordedRequest {
+	t.Helper()
+	calls := m.Calls(method, path)
+	if len(calls) == 0 {
+		t.Errorf("Expected a %s %s request, got none", method, path)
+		return RecordedRequest{}
+	}
+	return calls[len(calls)-1]
+}
+
+// AssertCallCount fails the test unless exactly n requests with the given
+// method and URL path were received
+func (m *MockDoer) AssertCallCount(t testing.TB, method, path string, n int) {
+	t.Helper()
+	if got := len(m.Calls(method, path)); got != n {
+		t.Errorf("Expected %d %s %s requests, got %d", n, method, path, got)
+	}
+}
+
+// AssertNotCalled fails the test if a request with the given method and URL
+// path was received
+func (m *MockDoer) AssertNotCalled(t testing.TB, method, path string) {
+	t.Helper()
+	m.AssertCallCount(t, method, path, 0)
+}
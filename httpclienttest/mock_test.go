@@ -0,0 +1,99 @@
+package httpclienttest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	httpclient "github.com/futuretea/go-http-client"
+)
+
+func TestMockDoer_Routes(t *testing.T) {
+	mock := NewMockDoer().
+		On(http.MethodGet, "/api/v1/users/1", JSONResponse(http.StatusOK, map[string]string{"name": "alice"})).
+		On(http.MethodDelete, "/api/v1/users/1", MockResponse{StatusCode: http.StatusNoContent}).
+		On(http.MethodGet, "/api/v1/users/2", JSONResponse(http.StatusNotFound, map[string]string{"message": "no such user"}))
+
+	client := httpclient.NewClient(&httpclient.Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}, httpclient.WithHTTPClient(mock))
+
+	var user map[string]string
+	if err := client.GET("/api/v1/users/1").Do(&user); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if user["name"] != "alice" {
+		t.Errorf("Expected name 'alice', got '%s'", user["name"])
+	}
+
+	if err := client.DELETE("/api/v1/users/1").WithHeader("X-Reason", "cleanup").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	err := client.GET("/api/v1/users/2").Do(&user)
+	var apiErr *httpclient.APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() || apiErr.Message != "no such user" {
+		t.Errorf("Expected 404 APIError 'no such user', got %v", err)
+	}
+
+	mock.AssertCallCount(t, http.MethodGet, "/api/v1/users/1", 1)
+	mock.AssertNotCalled(t, http.MethodPost, "/api/v1/users")
+	req := mock.AssertCalled(t, http.MethodDelete, "/api/v1/users/1")
+	if got := req.Header.Get("X-Reason"); got != "cleanup" {
+		t.Errorf("Expected X-Reason 'cleanup', got '%s'", got)
+	}
+	if got := len(mock.Requests()); got != 3 {
+		t.Errorf("Expected 3 recorded requests, got %d", got)
+	}
+}
+
+func TestMockDoer_Queue(t *testing.T) {
+	mock := NewMockDoer().Enqueue(
+		MockResponse{StatusCode: http.StatusServiceUnavailable},
+		MockResponse{StatusCode: http.StatusCreated, Body: []byte(`{"id":"42"}`)},
+	)
+
+	client := httpclient.NewClient(&httpclient.Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}, httpclient.WithHTTPClient(mock), httpclient.WithRetry(3, time.Millisecond, 10*time.Millisecond))
+
+	var created map[string]string
+	err := client.POST("/api/v1/users").
+		WithJSON(map[string]string{"name": "bob"}).
+		Do(&created)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if created["id"] != "42" {
+		t.Errorf("Expected id '42', got '%s'", created["id"])
+	}
+
+	calls := mock.Calls(http.MethodPost, "/api/v1/users")
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(calls))
+	}
+	if string(calls[1].Body) != `{"name":"bob"}` {
+		t.Errorf("Expected retried body to be sent again, got '%s'", calls[1].Body)
+	}
+
+	// Once the queue is empty, requests fail
+	if err := client.GET("/api/v1/other").Do(nil); err == nil {
+		t.Error("Expected error for a request without a mock response")
+	}
+}
+
+func TestMockDoer_Err(t *testing.T) {
+	mock := NewMockDoer().On("", "/api/v1/down", MockResponse{Err: errors.New("connection refused")})
+
+	client := httpclient.NewClient(&httpclient.Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}, httpclient.WithHTTPClient(mock))
+
+	if err := client.GET("/api/v1/down").Do(nil); err == nil || err.Error() != "connection refused" {
+		t.Errorf("Expected 'connection refused', got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ParseLinkHeader parses the RFC 8288 (formerly RFC 5988) Link headers in h,
+// as used for pagination, and returns the target URLs keyed by rel, such as
+// "next" and "prev". A link with several space-separated rels is listed under
+// each of them, and the first link wins for a repeated rel. Other parameters
+// are ignored.
+//
+// Example usage:
+//
+//	resp, err := client.GET("/api/v1/users").DoWithResponse()
+//	// ...
+//	next, ok := httpclient.ParseLinkHeader(resp.Header)["next"]
+func ParseLinkHeader(h http.Header) map[string]string {
+	links := make(map[string]string)
+	for _, value := range h.Values("Link") {
+		for value != "" {
+			var target string
+			var params map[string]string
+			target, params, value = parseLink(value)
+			if target == "" {
+				continue
+			}
+			for _, rel := range strings.Fields(params["rel"]) {
+				rel = strings.ToLower(rel)
+				if _, ok := links[rel]; !ok {
+					links[rel] = target
+				}
+			}
+		}
+	}
+	return links
+}
+
+// Links returns the URLs of the response's Link headers keyed by rel, as
+// parsed by ParseLinkHeader
+func (r *Response) Links() map[string]string {
+	return ParseLinkHeader(r.Header)
+}
+
+// parseLink parses the first link-value of s, returning its target, its
+// parameters keyed by lower-case name and the rest of s after the separating
+// comma. A malformed link-value yields an empty target and is skipped.
+func parseLink(s string) (target string, params map[string]string, rest string) {
+	s = strings.TrimLeft(s, " \t,")
+	if !strings.HasPrefix(s, "<") {
+		// Skip to the next link-value
+		if i := strings.IndexByte(s, ','); i >= 0 {
+			return "", nil, s[i+1:]
+		}
+		return "", nil, ""
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 0 {
+		return "", nil, ""
+	}
+	target, s = strings.TrimSpace(s[1:end]), s[end+1:]
+
+	params = make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" || s[0] == ',' {
+			break
+		}
+		if s[0] != ';' {
+			// Ignore stray characters up to the next parameter or link-value
+			s = s[strings.IndexAny(s+",", ";,"):]
+			continue
+		}
+		s = strings.TrimLeft(s[1:], " \t")
+
+		nameEnd := strings.IndexAny(s+"=", "=;, \t")
+		name := strings.ToLower(s[:nameEnd])
+		s = strings.TrimLeft(s[nameEnd:], " \t")
+
+		var value string
+		if strings.HasPrefix(s, "=") {
+			value, s = parseLinkParamValue(strings.TrimLeft(s[1:], " \t"))
+		}
+		if _, ok := params[name]; !ok && name != "" {
+			params[name] = value
+		}
+	}
+	if s != "" {
+		s = s[1:]
+	}
+	return target, params, s
+}
+
+// parseLinkParamValue parses a token or quoted-string parameter value at the
+// start of s and returns it along with the rest of s
+func parseLinkParamValue(s string) (string, string) {
+	if !strings.HasPrefix(s, `"`) {
+		end := strings.IndexAny(s+";", ";, \t")
+		return s[:end], s[end:]
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:]
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), ""
+}
@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	h := http.Header{}
+	h.Add("Link", `<https://api.example.com/items?page=2>; rel="next", `+
+		`<https://api.example.com/items?page=9>; title="last, final"; rel=last`)
+	h.Add("Link", `<https://api.example.com/items?page=1>;rel="prev first"`)
+	h.Add("Link", `<https://api.example.com/other>; rel="next"`)
+
+	links := ParseLinkHeader(h)
+
+	expected := map[string]string{
+		"next":  "https://api.example.com/items?page=2",
+		"last":  "https://api.example.com/items?page=9",
+		"prev":  "https://api.example.com/items?page=1",
+		"first": "https://api.example.com/items?page=1",
+	}
+	if len(links) != len(expected) {
+		t.Errorf("Expected %d links, got %d: %v", len(expected), len(links), links)
+	}
+	for rel, url := range expected {
+		if links[rel] != url {
+			t.Errorf("Expected rel %s to be %s, got %s", rel, url, links[rel])
+		}
+	}
+}
+
+func TestParseLinkHeader_Malformed(t *testing.T) {
+	h := http.Header{}
+	h.Set("Link", `garbage, <https://api.example.com/items?page=2>; rel="next"; title="a \"quoted\" title", <unterminated`)
+
+	links := ParseLinkHeader(h)
+	if len(links) != 1 || links["next"] != "https://api.example.com/items?page=2" {
+		t.Errorf("Expected only the next link, got %v", links)
+	}
+}
+
+func TestResponse_Links(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Link", `</items?page=2>; rel="next"`)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var items []string
+	resp, err := client.GET("/items").DoResponse(&items)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if next := resp.Links()["next"]; next != "/items?page=2" {
+		t.Errorf("Expected next link '/items?page=2', got '%s'", next)
+	}
+}
@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestMetric describes a completed call
+type RequestMetric struct {
+	Method string
+	// Path is the path as passed to GET/POST/etc., before path parameters are expanded,
+	// which keeps label cardinality low
+	Path string
+	// StatusCode is 0 when no response was received
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+	// Labels holds the labels set with RequestBuilder.WithMetricLabel
+	Labels map[string]string
+}
+
+// MetricsRecorder receives a metric for every call made by the client
+type MetricsRecorder interface {
+	ObserveRequest(m RequestMetric)
+}
+
+// Conventional names for the counters reported to a RetryMetricsRecorder
+const (
+	MetricRetriesTotal        = "http_client_retries_total"
+	MetricRetryExhaustedTotal = "http_client_retry_exhausted_total"
+)
+
+// RetryMetric describes a retried attempt, or a call that ran out of retry attempts
+type RetryMetric struct {
+	Method string
+	// Path is the unexpanded path, as in RequestMetric
+	Path string
+	// StatusCode of the attempt, 0 when it failed without a response
+	StatusCode int
+	Labels     map[string]string
+}
+
+// RetryMetricsRecorder is implemented by a MetricsRecorder that also counts retries.
+// ObserveRetry is called for every attempt that is retried (MetricRetriesTotal) and
+// ObserveRetryExhausted when the final attempt still failed (MetricRetryExhaustedTotal).
+type RetryMetricsRecorder interface {
+	ObserveRetry(m RetryMetric)
+	ObserveRetryExhausted(m RetryMetric)
+}
+
+// MetricsRecorderFunc adapts a function to the MetricsRecorder interface
+type MetricsRecorderFunc func(m RequestMetric)
+
+// ObserveRequest calls f(m)
+func (f MetricsRecorderFunc) ObserveRequest(m RequestMetric) {
+	f(m)
+}
+
+// WithMetrics reports a RequestMetric to recorder for every call, covering
+// all retry attempts
+func WithMetrics(recorder MetricsRecorder) Option {
+	return func(c *HTTPClient) {
+		c.metrics = recorder
+	}
+}
+
+// WithMetricLabel adds a custom label to the metrics recorded for this request,
+// such as an operation name. Labels are also attached to the request context
+// and can be read by middleware with MetricLabelsFromContext.
+func (b *RequestBuilder) WithMetricLabel(key, value string) *RequestBuilder {
+	if b.metricLabels == nil {
+		b.metricLabels = make(map[string]string)
+	}
+	b.metricLabels[key] = value
+	return b
+}
+
+// MetricLabelsFromContext returns the labels set with WithMetricLabel
+func MetricLabelsFromContext(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(metricLabelsKey).(map[string]string)
+	return labels
+}
+
+// RequestStartFromContext returns the time the call was sent, for middleware
+// to measure latency. It is set for calls made through the Do methods.
+func RequestStartFromContext(ctx context.Context) (time.Time, bool) {
+	start, ok := ctx.Value(callStartKey).(time.Time)
+	return start, ok
+}
+
+// recordMetric reports a completed call to the client's recorder, if any
+func (b *RequestBuilder) recordMetric(resp *http.Response, start time.Time, err error) {
+	if b.client.metrics == nil {
+		return
+	}
+
+	m := RequestMetric{
+		Method:   b.method,
+		Path:     b.path,
+		Duration: time.Since(start),
+		Err:      err,
+		Labels:   b.metricLabels,
+	}
+	if resp != nil {
+		m.StatusCode = resp.StatusCode
+	}
+	b.client.metrics.ObserveRequest(m)
+}
+
+// retryObserver reports retries to the client's recorder if it counts them
+func (b *RequestBuilder) retryObserver() retryObserver {
+	recorder, ok := b.client.metrics.(RetryMetricsRecorder)
+	if !ok {
+		return nil
+	}
+
+	return func(resp *http.Response, _ error, exhausted bool) {
+		m := RetryMetric{
+			Method: b.method,
+			Path:   b.path,
+			Labels: b.metricLabels,
+		}
+		if resp != nil {
+			m.StatusCode = resp.StatusCode
+		}
+		if exhausted {
+			recorder.ObserveRetryExhausted(m)
+		} else {
+			recorder.ObserveRetry(m)
+		}
+	}
+}
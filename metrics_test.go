@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithMetricLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var recorded []RequestMetric
+	var ctxLabels map[string]string
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	},
+		WithMetrics(MetricsRecorderFunc(func(m RequestMetric) {
+			recorded = append(recorded, m)
+		})),
+		WithMiddleware(func(req *http.Request) error {
+			ctxLabels = MetricLabelsFromContext(req.Context())
+			return nil
+		}))
+
+	err := client.GET("/users/{id}").
+		WithPathParam("id", "42").
+		WithMetricLabel("operation", "get_user").
+		Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 recorded metric, got %d", len(recorded))
+	}
+	m := recorded[0]
+	if m.Labels["operation"] != "get_user" {
+		t.Errorf("Expected operation label 'get_user', got '%s'", m.Labels["operation"])
+	}
+	if m.Method != http.MethodGet || m.Path != "/users/{id}" {
+		t.Errorf("Expected GET /users/{id}, got %s %s", m.Method, m.Path)
+	}
+	if m.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", m.StatusCode)
+	}
+	if ctxLabels["operation"] != "get_user" {
+		t.Errorf("Expected label in request context, got %v", ctxLabels)
+	}
+}
+
+// counterRecorder counts retry metrics by name, method and status
+type counterRecorder struct {
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+func (r *counterRecorder) ObserveRequest(RequestMetric) {}
+
+func (r *counterRecorder) ObserveRetry(m RetryMetric) {
+	r.inc(MetricRetriesTotal, m)
+}
+
+func (r *counterRecorder) ObserveRetryExhausted(m RetryMetric) {
+	r.inc(MetricRetryExhaustedTotal, m)
+}
+
+func (r *counterRecorder) inc(name string, m RetryMetric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[fmt.Sprintf("%s{method=%s,status=%d}", name, m.Method, m.StatusCode)]++
+}
+
+func TestRetryMetrics(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		if r.URL.Path == "/down" || n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := &counterRecorder{counters: make(map[string]int)}
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetry(3, time.Millisecond, 5*time.Millisecond), WithMetrics(recorder))
+
+	// 503 -> 503 -> 200
+	if err := client.GET("/flaky").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	expected := map[string]int{
+		"http_client_retries_total{method=GET,status=503}": 2,
+	}
+	if !reflect.DeepEqual(recorder.counters, expected) {
+		t.Errorf("Expected counters %v, got %v", expected, recorder.counters)
+	}
+
+	// 503 -> 503 -> 503
+	if err := client.POST("/down").Do(nil); err == nil {
+		t.Fatal("Expected error after retries were exhausted")
+	}
+
+	expected = map[string]int{
+		"http_client_retries_total{method=GET,status=503}":          2,
+		"http_client_retries_total{method=POST,status=503}":         2,
+		"http_client_retry_exhausted_total{method=POST,status=503}": 1,
+	}
+	if !reflect.DeepEqual(recorder.counters, expected) {
+		t.Errorf("Expected counters %v, got %v", expected, recorder.counters)
+	}
+}
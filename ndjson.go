@@ -0,0 +1,52 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DoNDJSON executes the request and decodes a newline-delimited JSON response
+// one value at a time as it arrives, without buffering the body. For each value
+// it decodes into a fresh newElem() and passes it to onElem; an error from
+// onElem stops the stream and is returned. Error statuses are handled as in Do.
+//
+// Example usage:
+//
+//	err := client.GET("/export").DoNDJSON(
+//	    func() interface{} { return &Record{} },
+//	    func(v interface{}) error { return process(v.(*Record)) })
+func (b *RequestBuilder) DoNDJSON(newElem func() interface{}, onElem func(interface{}) error) error {
+	if b.err != nil {
+		return b.err
+	}
+	if err := b.consume(); err != nil {
+		return err
+	}
+
+	b.streaming = true
+	resp, err := b.execute()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return b.client.handleErrorResponse(resp)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		elem := newElem()
+		if err := dec.Decode(elem); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		if err := onElem(elem); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestBuilder_DoNDJSON(t *testing.T) {
+	firstReceived := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 0; i < 1000; i++ {
+			_, _ = fmt.Fprintf(w, "{\"id\":%d}\n", i)
+			if i == 0 {
+				// Hold the rest back until the client has handled the first record
+				w.(http.Flusher).Flush()
+				select {
+				case <-firstReceived:
+				case <-time.After(5 * time.Second):
+					t.Error("Expected first record to be handled before the body was complete")
+					return
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 10 * time.Second,
+	})
+
+	type record struct {
+		ID int `json:"id"`
+	}
+	var ids []int
+	err := client.GET("/export").DoNDJSON(
+		func() interface{} { return &record{} },
+		func(v interface{}) error {
+			ids = append(ids, v.(*record).ID)
+			if len(ids) == 1 {
+				close(firstReceived)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("DoNDJSON failed: %v", err)
+	}
+
+	if len(ids) != 1000 {
+		t.Fatalf("Expected 1000 records, got %d", len(ids))
+	}
+	for i, id := range ids {
+		if id != i {
+			t.Fatalf("Expected record %d to have id %d, got %d", i, i, id)
+		}
+	}
+}
+
+func TestRequestBuilder_DoNDJSON_StopsOnCallbackError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := client.GET("/export").DoNDJSON(
+		func() interface{} { return &map[string]int{} },
+		func(interface{}) error {
+			calls++
+			if calls == 2 {
+				return errStop
+			}
+			return nil
+		})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Expected callback error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
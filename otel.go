@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OTelMiddleware returns a middleware that starts a client span for each call
+// with tracer and injects the trace context into the request headers using the
+// global propagator (see otel.SetTextMapPropagator). The span becomes part of the
+// request's context. It is ended once the call completes, recording the status
+// code or the error of whichever stage the call failed at, be it later middleware,
+// the transport or response middleware. 4xx and 5xx responses mark it as failed.
+// Requests built with BuildRequest are not sent by the client, so their span ends
+// right away.
+func OTelMiddleware(tracer trace.Tracer) Middleware {
+	return func(req *http.Request) error {
+		ctx, span := tracer.Start(req.Context(), "HTTP "+req.Method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("http.method", req.Method),
+				attribute.String("http.url", req.URL.Redacted()),
+			))
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		// Replace the context in place so later stages of the call see the span
+		*req = *req.WithContext(ctx)
+
+		if !onCallFinish(ctx, func(resp *http.Response, err error) { endSpan(span, resp, err) }) {
+			span.End()
+		}
+		return nil
+	}
+}
+
+// endSpan records the outcome of a call on span and ends it
+func endSpan(span trace.Span, resp *http.Response, err error) {
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp != nil:
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+	span.End()
+}
+
+// WithTracing traces every call with tracer using OTelMiddleware
+//
+// Example usage:
+//
+//	otel.SetTextMapPropagator(propagation.TraceContext{})
+//	client := httpclient.NewClient(config,
+//	    httpclient.WithTracing(otel.Tracer("my-service")))
+func WithTracing(tracer trace.Tracer) Option {
+	return func(c *HTTPClient) {
+		c.middleware = append(c.middleware, OTelMiddleware(tracer))
+	}
+}
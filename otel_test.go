@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTestTracer returns a tracer whose spans are recorded in memory, with the
+// W3C trace context propagator installed for the duration of the test
+func newTestTracer(t *testing.T) (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	t.Helper()
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+}
+
+// spanAttr returns the value of the attribute key of a recorded span
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestOTelMiddleware(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	recorder, provider := newTestTracer(t)
+	tracer := provider.Tracer("test")
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(OTelMiddleware(tracer)))
+
+	_, _ = client.GET("/users/1").DoStatus()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 ended span, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if method, _ := spanAttr(span, "http.method"); method.AsString() != http.MethodGet {
+		t.Errorf("Expected http.method GET, got %q", method.AsString())
+	}
+	if status, _ := spanAttr(span, "http.status_code"); status.AsInt64() != http.StatusNotFound {
+		t.Errorf("Expected http.status_code 404, got %d", status.AsInt64())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", span.Status().Code)
+	}
+
+	want := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("Expected traceparent %s, got %s", want, traceparent)
+	}
+}
+
+func TestWithTracing_TransportError(t *testing.T) {
+	recorder, provider := newTestTracer(t)
+	client := NewClient(&Config{
+		BaseURL: "http://127.0.0.1:1",
+		Timeout: 5 * time.Second,
+	}, WithTracing(provider.Tracer("test")))
+
+	_, err := client.GET("/users/1").DoStatus()
+	if err == nil {
+		t.Fatal("Expected transport error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 ended span, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", spans[0].Status().Code)
+	}
+	if len(spans[0].Events()) == 0 {
+		t.Error("Expected error to be recorded on the span")
+	}
+}
+
+func TestOTelMiddleware_EndsSpanOnFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	failing := func(*http.Request) error { return errors.New("signing failed") }
+	tests := []struct {
+		name    string
+		baseURL string
+		opts    []Option
+	}{
+		{
+			name:    "later middleware error",
+			baseURL: server.URL,
+			opts:    []Option{WithMiddleware(failing)},
+		},
+		{
+			name:    "response middleware error",
+			baseURL: server.URL,
+			opts: []Option{WithResponseMiddleware(func(*http.Response) error {
+				return errors.New("rejected")
+			})},
+		},
+		{
+			name:    "transport error",
+			baseURL: "http://127.0.0.1:1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, provider := newTestTracer(t)
+			opts := append([]Option{WithMiddleware(OTelMiddleware(provider.Tracer("test")))}, tt.opts...)
+			client := NewClient(&Config{
+				BaseURL: tt.baseURL,
+				Timeout: 5 * time.Second,
+			}, opts...)
+
+			if err := client.GET("/users/1").Do(nil); err == nil {
+				t.Fatal("Expected request to fail")
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("Expected 1 ended span, got %d", len(spans))
+			}
+			if spans[0].Status().Code != codes.Error {
+				t.Errorf("Expected error status, got %v", spans[0].Status().Code)
+			}
+			if len(recorder.Started()) != 1 {
+				t.Errorf("Expected 1 started span, got %d", len(recorder.Started()))
+			}
+		})
+	}
+}
+
+func TestOTelMiddleware_BuildRequest(t *testing.T) {
+	recorder, provider := newTestTracer(t)
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}, WithTracing(provider.Tracer("test")))
+
+	req, err := client.GET("/users/1").BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+	if req.Header.Get("Traceparent") == "" {
+		t.Error("Expected traceparent header to be injected")
+	}
+	if len(recorder.Ended()) != 1 {
+		t.Errorf("Expected span of an unsent request to be ended, got %d ended spans", len(recorder.Ended()))
+	}
+}
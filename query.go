@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// QueryEncoder is implemented by types that control their own query string encoding
+type QueryEncoder interface {
+	EncodeQuery() string
+}
+
+var queryEncoderType = reflect.TypeOf((*QueryEncoder)(nil)).Elem()
+
+// WithQueryStruct adds query parameters from the exported fields of a struct.
+// Field names come from the `url` tag (`url:"name,omitempty"`), defaulting to
+// the field name; a tag of "-" skips the field. Slices add one value per element.
+// Types implementing QueryEncoder, with a value or pointer receiver, encode themselves.
+//
+// Example usage:
+//
+//	type ListOptions struct {
+//	    Page  int      `url:"page"`
+//	    Tags  []string `url:"tag,omitempty"`
+//	}
+//	client.GET("/api/v1/items").WithQueryStruct(ListOptions{Page: 2})
+func (b *RequestBuilder) WithQueryStruct(v interface{}) *RequestBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	values, err := encodeQueryStruct(v)
+	if err != nil {
+		b.err = fmt.Errorf("failed to encode query struct: %w", err)
+		return b
+	}
+
+	if b.query == nil {
+		b.query = url.Values{}
+	}
+	for k, vs := range values {
+		for _, v := range vs {
+			b.query.Add(k, v)
+		}
+	}
+	return b
+}
+
+// encodeQueryStruct converts a struct (or pointer to struct) into url.Values
+func encodeQueryStruct(v interface{}) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct, got %s", rv.Kind())
+	}
+
+	// Copy a struct passed by value so that fields with a pointer receiver
+	// QueryEncoder can be addressed
+	if !rv.CanAddr() {
+		addressable := reflect.New(rv.Type()).Elem()
+		addressable.Set(rv)
+		rv = addressable
+	}
+
+	values := url.Values{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty := parseQueryTag(field)
+		if name == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		if err := addQueryValue(values, name, fv); err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return values, nil
+}
+
+// parseQueryTag returns the query parameter name and omitempty flag for a field
+func parseQueryTag(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("url")
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, opts == "omitempty"
+}
+
+// addQueryValue adds the encoding of fv to values under name
+func addQueryValue(values url.Values, name string, fv reflect.Value) error {
+	if fv.Type().Implements(queryEncoderType) {
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			return nil
+		}
+		values.Add(name, fv.Interface().(QueryEncoder).EncodeQuery())
+		return nil
+	}
+	if fv.CanAddr() && fv.Addr().Type().Implements(queryEncoderType) {
+		values.Add(name, fv.Addr().Interface().(QueryEncoder).EncodeQuery())
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if fv.IsNil() {
+			return nil
+		}
+		return addQueryValue(values, name, fv.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			if err := addQueryValue(values, name, fv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.String:
+		values.Add(name, fv.String())
+	case reflect.Bool:
+		values.Add(name, strconv.FormatBool(fv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Add(name, strconv.FormatInt(fv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Add(name, strconv.FormatUint(fv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(name, strconv.FormatFloat(fv.Float(), 'f', -1, fv.Type().Bits()))
+	default:
+		if s, ok := fv.Interface().(fmt.Stringer); ok {
+			values.Add(name, s.String())
+			return nil
+		}
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
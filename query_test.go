@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+)
+
+// queryTime encodes a time.Time as RFC3339 in query strings
+type queryTime struct {
+	time.Time
+}
+
+func (t queryTime) EncodeQuery() string {
+	return t.Format(time.RFC3339)
+}
+
+func TestRequestBuilder_WithQueryStruct(t *testing.T) {
+	type listOptions struct {
+		Since  queryTime `url:"since"`
+		Page   int       `url:"page"`
+		Tags   []string  `url:"tag"`
+		Cursor string    `url:"cursor,omitempty"`
+		Secret string    `url:"-"`
+	}
+
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	req, err := client.GET("/api/v1/items").
+		WithQueryStruct(listOptions{
+			Since:  queryTime{since},
+			Page:   2,
+			Tags:   []string{"a", "b"},
+			Secret: "hidden",
+		}).
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+
+	query := req.URL.Query()
+	if got := query.Get("since"); got != "2024-03-01T12:00:00Z" {
+		t.Errorf("Expected since encoded as RFC3339, got '%s'", got)
+	}
+	if got := query.Get("page"); got != "2" {
+		t.Errorf("Expected page=2, got '%s'", got)
+	}
+	if got := query["tag"]; len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Expected tag=a&tag=b, got %v", got)
+	}
+	if query.Has("cursor") || query.Has("Secret") {
+		t.Errorf("Expected omitted fields to be absent, got %v", query)
+	}
+}
+
+// querySort encodes a sort order with a pointer receiver
+type querySort struct {
+	Field string
+	Desc  bool
+}
+
+func (s *querySort) EncodeQuery() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+func TestRequestBuilder_WithQueryStruct_PointerReceiver(t *testing.T) {
+	type listOptions struct {
+		Sort  querySort   `url:"sort"`
+		Extra []querySort `url:"extra"`
+	}
+
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	opts := listOptions{
+		Sort:  querySort{Field: "created", Desc: true},
+		Extra: []querySort{{Field: "name"}},
+	}
+	for _, v := range []interface{}{opts, &opts} {
+		req, err := client.GET("/api/v1/items").WithQueryStruct(v).BuildRequest()
+		if err != nil {
+			t.Fatalf("BuildRequest failed: %v", err)
+		}
+
+		query := req.URL.Query()
+		if got := query.Get("sort"); got != "-created" {
+			t.Errorf("Expected sort=-created, got '%s'", got)
+		}
+		if got := query.Get("extra"); got != "name" {
+			t.Errorf("Expected extra=name, got '%s'", got)
+		}
+	}
+}
+
+func TestRequestBuilder_WithQueryStruct_NotStruct(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	if _, err := client.GET("/api/v1/items").WithQueryStruct("page=1").BuildRequest(); err == nil {
+		t.Fatal("Expected error for non-struct query value")
+	}
+}
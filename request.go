@@ -2,35 +2,156 @@ package httpclient
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
-// RequestBuilder provides a fluent API for building HTTP requests
+// RequestBuilder provides a fluent API for building HTTP requests.
+// A builder is single-use: executing it again returns ErrBuilderConsumed.
+// Use Fork to share a preconfigured builder between calls.
 type RequestBuilder struct {
 	client  *HTTPClient
 	method  string
 	path    string
 	body    []byte
-	headers map[string]string
+	bodySrc bodySource
+	headers http.Header
 	query   url.Values
 	ctx     context.Context
 	err     error
+
+	requestID   string
+	contentType string
+	chunked     bool
+	basePath    *string
+	gzipLevel   *int
+
+	pathParams   map[string]string
+	metricLabels map[string]string
+
+	// headers applied after middleware, only when not already set
+	headersIfAbsent http.Header
+
+	// JSON Schema the 2xx response body must match
+	schema *jsonschema.Schema
+
+	// rewrites the 2xx response body before it is decoded
+	transform func([]byte) ([]byte, error)
+
+	// reader streamed by bodySrc, set by WithBodyReader
+	bodyReader io.Reader
+
+	// set on a fork that dropped a WithBodyReader body, until a body is set
+	bodyForked bool
+
+	// streaming hands response middleware the live body instead of a buffered copy
+	streaming bool
+
+	// set once the request has been executed; builders are single-use
+	consumed bool
 }
 
+// bodySource opens a streamed request body and reports its length (-1 if unknown)
+type bodySource func() (io.ReadCloser, int64, error)
+
 // Middleware is a function that can inspect/modify HTTP requests before they are sent
 type Middleware func(*http.Request) error
 
+// SkipRequest returns an error for a middleware to return to skip sending the
+// request and serve resp instead, e.g. on a cache hit. The response goes through
+// response middleware and is handled like one from the server, so a non-2xx
+// status fails the call. A nil Body reads as empty. The error matches ErrSkipRequest.
+//
+// Example usage:
+//
+//	func(req *http.Request) error {
+//	    if body, ok := cache.Get(req.URL.String()); ok {
+//	        return httpclient.SkipRequest(&http.Response{
+//	            StatusCode: http.StatusOK,
+//	            Header:     http.Header{"Content-Type": {"application/json"}},
+//	            Body:       io.NopCloser(bytes.NewReader(body)),
+//	        })
+//	    }
+//	    return nil
+//	}
+func SkipRequest(resp *http.Response) error {
+	return &skipRequestError{resp: resp}
+}
+
+// skipRequestError carries the response a middleware serves in place of the server's
+type skipRequestError struct {
+	resp *http.Response
+}
+
+// Error implements the error interface
+func (e *skipRequestError) Error() string {
+	return ErrSkipRequest.Error()
+}
+
+// Is makes the error match ErrSkipRequest
+func (e *skipRequestError) Is(target error) bool {
+	return target == ErrSkipRequest
+}
+
+// skippedResponse returns the response served for req by a middleware that
+// returned err, or false if err does not skip the request
+func skippedResponse(req *http.Request, err error) (*http.Response, bool) {
+	if !errors.Is(err, ErrSkipRequest) {
+		return nil, false
+	}
+
+	resp := &http.Response{StatusCode: http.StatusNoContent}
+	var skip *skipRequestError
+	if errors.As(err, &skip) && skip.resp != nil {
+		resp = skip.resp
+	}
+
+	if resp.Status == "" {
+		resp.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if resp.Proto == "" {
+		resp.Proto, resp.ProtoMajor, resp.ProtoMinor = "HTTP/1.1", 1, 1
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	if resp.Body == nil {
+		resp.Body = http.NoBody
+	} else if resp.ContentLength == 0 && resp.Body != http.NoBody {
+		// A zero length would mark the response as empty
+		resp.ContentLength = -1
+	}
+	resp.Request = req
+	return resp, true
+}
+
+// SuccessValidator inspects a 2xx response and its body, returning an error
+// for responses that signal a logical failure despite the status code
+type SuccessValidator func(resp *http.Response, body []byte) error
+
+// ResultValidator inspects a decoded result, returning an error for results
+// that signal a logical failure
+type ResultValidator func(result interface{}) error
+
 // ResponseMiddleware is a function that can inspect/modify HTTP responses after they are received
-// The response body will be restored after middleware execution
+// The response body will be restored after middleware execution. Middleware runs in
+// registration order, each one reading the body from the start, and an error skips
+// the rest. A middleware that replaces the body passes the new one on.
 type ResponseMiddleware func(*http.Response) error
 
-// GET sets the HTTP method to GET
+// GET sets the HTTP method to GET.
+// Paths are resolved against the client's base URL, while an absolute URL
+// (with a scheme and host) is used verbatim. The same applies to the other methods.
 func (b *RequestBuilder) GET(path string) *RequestBuilder {
 	b.method = http.MethodGet
 	b.path = path
@@ -65,6 +186,80 @@ func (b *RequestBuilder) PATCH(path string) *RequestBuilder {
 	return b
 }
 
+// HEAD sets the HTTP method to HEAD. Do leaves the result untouched, as HEAD
+// responses have no body; use DoResponse to read the headers.
+func (b *RequestBuilder) HEAD(path string) *RequestBuilder {
+	b.method = http.MethodHead
+	b.path = path
+	return b
+}
+
+// OPTIONS sets the HTTP method to OPTIONS
+func (b *RequestBuilder) OPTIONS(path string) *RequestBuilder {
+	b.method = http.MethodOptions
+	b.path = path
+	return b
+}
+
+// When applies fn to the builder only if cond is true, keeping the chain fluent
+//
+// Example usage:
+//
+//	client.GET("/api/v1/users").
+//	    When(verbose, func(b *httpclient.RequestBuilder) *httpclient.RequestBuilder {
+//	        return b.WithQuery("expand", "all")
+//	    }).
+//	    Do(&users)
+func (b *RequestBuilder) When(cond bool, fn func(*RequestBuilder) *RequestBuilder) *RequestBuilder {
+	if !cond {
+		return b
+	}
+	return fn(b)
+}
+
+// Fork returns an independent copy of the builder. Builders are single-use, so
+// a preconfigured builder shared between calls, or goroutines, should be forked
+// for each call and never executed itself. The copy shares the context.
+// A body set with WithBodyReader cannot be shared, so the copy has none and
+// fails with ErrBodyReaderForked unless a body is set on it.
+//
+// Example usage:
+//
+//	base := client.GET("/api/v1/search").WithHeader("X-Tenant", tenant)
+//	err := base.Fork().WithQuery("q", "foo").Do(&result)
+func (b *RequestBuilder) Fork() *RequestBuilder {
+	f := *b
+	f.headers = b.headers.Clone()
+	f.headersIfAbsent = b.headersIfAbsent.Clone()
+	f.query = cloneValues(b.query)
+	f.pathParams = cloneMap(b.pathParams)
+	f.metricLabels = cloneMap(b.metricLabels)
+	f.consumed = false
+	if f.bodySrc != nil && f.bodyReader != nil {
+		// The reader cannot be shared, so the fork needs a body of its own
+		f.bodySrc, f.bodyReader, f.bodyForked = nil, nil, true
+	}
+	return &f
+}
+
+// consume marks the builder as executed, failing with ErrBuilderConsumed if it already was
+func (b *RequestBuilder) consume() error {
+	if b.consumed {
+		return ErrBuilderConsumed
+	}
+	b.consumed = true
+	return nil
+}
+
+// WithBasePath replaces the path of the client's base URL for this request,
+// keeping its scheme and host. For example, with a base URL of
+// https://api.example.com/v1, WithBasePath("/v2") sends GET("/users") to
+// https://api.example.com/v2/users.
+func (b *RequestBuilder) WithBasePath(prefix string) *RequestBuilder {
+	b.basePath = &prefix
+	return b
+}
+
 // WithContext sets the request context.
 // The context must not be nil.
 func (b *RequestBuilder) WithContext(ctx context.Context) *RequestBuilder {
@@ -74,38 +269,271 @@ func (b *RequestBuilder) WithContext(ctx context.Context) *RequestBuilder {
 
 // WithJSON serializes the given object as JSON and sets it as the request body
 // Automatically sets Content-Type: application/json
+// A JSON codec registered with WithCodec is used instead of encoding/json.
 func (b *RequestBuilder) WithJSON(v interface{}) *RequestBuilder {
+	codec, ok := b.client.codecFor(JSONCodec.ContentType())
+	if !ok {
+		codec = JSONCodec
+	}
+	return b.withCodecBody(codec, "JSON", v)
+}
+
+// WithXML serializes the given object as XML and sets it as the request body
+// Automatically sets Content-Type: application/xml
+// An XML codec registered with WithCodec is used instead of encoding/xml.
+func (b *RequestBuilder) WithXML(v interface{}) *RequestBuilder {
+	codec, ok := b.client.codecFor(XMLCodec.ContentType())
+	if !ok {
+		codec = XMLCodec
+	}
+	return b.withCodecBody(codec, "XML", v)
+}
+
+// WithEncodedBody serializes the given object with the codec registered for
+// contentType and sets it as the request body with that Content-Type
+func (b *RequestBuilder) WithEncodedBody(contentType string, v interface{}) *RequestBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	codec, ok := b.client.codecFor(contentType)
+	if !ok {
+		b.err = fmt.Errorf("no codec registered for content type %q", contentType)
+		return b
+	}
+	return b.withCodecBody(codec, codec.ContentType(), v)
+}
+
+// withCodecBody encodes v with codec and sets it as the request body
+func (b *RequestBuilder) withCodecBody(codec Codec, name string, v interface{}) *RequestBuilder {
 	if b.err != nil {
 		return b
 	}
 
-	data, err := json.Marshal(v)
+	data, err := codec.Encode(v)
 	if err != nil {
-		b.err = fmt.Errorf("failed to marshal JSON: %w", err)
+		b.err = fmt.Errorf("failed to marshal %s: %w", name, err)
 		return b
 	}
 
 	b.body = data
-	b.headers["Content-Type"] = "application/json"
+	b.bodySrc = nil
+	b.setDefaultContentType(codec.ContentType())
+	return b
+}
+
+// WithContentType sets the Content-Type header. Body helpers such as WithJSON
+// and WithForm keep it instead of setting their own, whichever is called first,
+// e.g. to send JSON as application/vnd.api+json.
+func (b *RequestBuilder) WithContentType(ct string) *RequestBuilder {
+	b.contentType = ct
+	b.headers.Set("Content-Type", ct)
+	return b
+}
+
+// setDefaultContentType sets the Content-Type for a body helper unless one was set with WithContentType
+func (b *RequestBuilder) setDefaultContentType(ct string) {
+	if b.contentType == "" {
+		b.headers.Set("Content-Type", ct)
+	}
+}
+
+// WithForm encodes the values as the request body
+// Automatically sets Content-Type: application/x-www-form-urlencoded
+func (b *RequestBuilder) WithForm(values url.Values) *RequestBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	b.body = []byte(values.Encode())
+	b.bodySrc = nil
+	b.setDefaultContentType("application/x-www-form-urlencoded")
 	return b
 }
 
+// WithFormMap encodes the map as a form request body, like WithForm
+func (b *RequestBuilder) WithFormMap(values map[string]string) *RequestBuilder {
+	form := url.Values{}
+	for k, v := range values {
+		form.Set(k, v)
+	}
+	return b.WithForm(form)
+}
+
 // WithBody sets the request body directly
 func (b *RequestBuilder) WithBody(body []byte) *RequestBuilder {
 	b.body = body
+	b.bodySrc = nil
+	return b
+}
+
+// WithFile streams the file at path as the request body and sets its Content-Type,
+// unless one was set with WithContentType.
+// The file is opened when the request is executed and closed once it has been sent.
+func (b *RequestBuilder) WithFile(path, contentType string) *RequestBuilder {
+	b.body = nil
+	b.bodyReader = nil
+	b.bodySrc = func() (io.ReadCloser, int64, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to open request body file: %w", err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, 0, fmt.Errorf("failed to stat request body file: %w", err)
+		}
+		return f, info.Size(), nil
+	}
+	if contentType != "" {
+		b.setDefaultContentType(contentType)
+	}
 	return b
 }
 
-// WithHeader sets a single header
+// WithBodyReader streams r as the request body without buffering it in memory.
+// An io.Seeker such as a *bytes.Reader or *os.File is sent with its remaining
+// length as Content-Length and rewound to its current offset when the request is
+// retried. Any other reader, such as a pipe, is sent once with chunked encoding,
+// so the request is not retried and the last attempt is returned as is. A reader
+// that is also an io.Closer is closed once it has been sent, unless it is seekable.
+// The reader cannot be shared, so a builder forked after WithBodyReader needs a
+// body of its own and fails with ErrBodyReaderForked otherwise.
+func (b *RequestBuilder) WithBodyReader(r io.Reader) *RequestBuilder {
+	b.body = nil
+	b.bodyReader = r
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		b.bodySrc = func() (io.ReadCloser, int64, error) {
+			if rc, ok := r.(io.ReadCloser); ok {
+				return rc, -1, nil
+			}
+			return io.NopCloser(r), -1, nil
+		}
+		return b
+	}
+
+	start := int64(-1)
+	b.bodySrc = func() (io.ReadCloser, int64, error) {
+		// Remember where the body starts on first use, then rewind to it
+		if start < 0 {
+			offset, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to seek request body: %w", err)
+			}
+			start = offset
+		}
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to seek request body: %w", err)
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, 0, fmt.Errorf("failed to seek request body: %w", err)
+		}
+		return io.NopCloser(r), end - start, nil
+	}
+	return b
+}
+
+// WithGzip compresses the request body with gzip at the given level and sets
+// Content-Encoding: gzip. Levels range from gzip.HuffmanOnly to gzip.BestCompression;
+// use gzip.DefaultCompression for the default tradeoff. It is a no-op for requests
+// without a body, and streamed bodies (WithFile) are sent uncompressed.
+func (b *RequestBuilder) WithGzip(level int) *RequestBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		b.err = fmt.Errorf("invalid gzip compression level: %d", level)
+		return b
+	}
+
+	b.gzipLevel = &level
+	return b
+}
+
+// WithResponseTransform rewrites the 2xx response body before it is decoded,
+// e.g. to unwrap an envelope such as {"data": {...}}. The body is buffered in
+// memory. DoWithRaw still returns the body as received.
+func (b *RequestBuilder) WithResponseTransform(fn func([]byte) ([]byte, error)) *RequestBuilder {
+	b.transform = fn
+	return b
+}
+
+// WithChunked sends the body with Transfer-Encoding: chunked, even when its
+// length is known. Only the framing changes, so retries replay the body the
+// same way they would without chunking.
+func (b *RequestBuilder) WithChunked() *RequestBuilder {
+	b.chunked = true
+	return b
+}
+
+// WithHeader sets a single header, replacing any values already set for key
 func (b *RequestBuilder) WithHeader(key, value string) *RequestBuilder {
-	b.headers[key] = value
+	b.headers.Set(key, value)
+	return b
+}
+
+// AddHeader adds a value to a header, keeping any values already set for key.
+// Use it to send repeated headers such as multiple X-Forwarded-For values.
+func (b *RequestBuilder) AddHeader(key, value string) *RequestBuilder {
+	b.headers.Add(key, value)
+	return b
+}
+
+// WithAccept sets the Accept header
+func (b *RequestBuilder) WithAccept(mediaType string) *RequestBuilder {
+	return b.WithHeader("Accept", mediaType)
+}
+
+// WithAcceptJSON sets Accept: application/json
+func (b *RequestBuilder) WithAcceptJSON() *RequestBuilder {
+	return b.WithAccept("application/json")
+}
+
+// WithAcceptXML sets Accept: application/xml
+func (b *RequestBuilder) WithAcceptXML() *RequestBuilder {
+	return b.WithAccept("application/xml")
+}
+
+// WithHeaderIfAbsent sets a header only if neither the request nor any
+// middleware has set it. It is applied after middleware runs.
+func (b *RequestBuilder) WithHeaderIfAbsent(key, value string) *RequestBuilder {
+	if b.headersIfAbsent == nil {
+		b.headersIfAbsent = make(http.Header)
+	}
+	b.headersIfAbsent.Set(key, value)
 	return b
 }
 
 // WithHeaders sets multiple headers
 func (b *RequestBuilder) WithHeaders(headers map[string]string) *RequestBuilder {
 	for k, v := range headers {
-		b.headers[k] = v
+		b.headers.Set(k, v)
+	}
+	return b
+}
+
+// WithPathParam sets the value of a {name} placeholder in the request path.
+// The value is URL path escaped.
+//
+// Example usage:
+//
+//	client.GET("/api/v1/users/{id}").WithPathParam("id", userID)
+func (b *RequestBuilder) WithPathParam(name, value string) *RequestBuilder {
+	if b.pathParams == nil {
+		b.pathParams = make(map[string]string)
+	}
+	b.pathParams[name] = value
+	return b
+}
+
+// WithPathParams sets the values of several {name} placeholders in the request path,
+// like WithPathParam
+func (b *RequestBuilder) WithPathParams(params map[string]string) *RequestBuilder {
+	for name, value := range params {
+		b.WithPathParam(name, value)
 	}
 	return b
 }
@@ -130,134 +558,734 @@ func (b *RequestBuilder) WithQueryParams(params map[string]string) *RequestBuild
 	return b
 }
 
+// mergedQuery returns the request query with the client's default query
+// parameters added for keys the request does not set
+func (b *RequestBuilder) mergedQuery() url.Values {
+	if len(b.client.defaultQuery) == 0 {
+		return b.query
+	}
+
+	merged := cloneValues(b.query)
+	if merged == nil {
+		merged = make(url.Values, len(b.client.defaultQuery))
+	}
+	for k, v := range b.client.defaultQuery {
+		if _, ok := merged[k]; !ok {
+			merged.Set(k, v)
+		}
+	}
+	return merged
+}
+
 // Do executes the HTTP request and parses the response
+// The response is decoded with the codec matching its Content-Type, falling back to JSON.
+// A result of type *[]byte, *string or io.Writer receives the raw body instead.
 func (b *RequestBuilder) Do(result interface{}) error {
+	_, err := b.DoResponse(result)
+	return err
+}
+
+// DoResponse executes the HTTP request like Do and also returns the response
+// metadata. The body is read to completion so trailers are available.
+func (b *RequestBuilder) DoResponse(result interface{}) (*Response, error) {
+	resp, _, err := b.do(result, nil, false)
+	return resp, err
+}
+
+// DoWithMeta executes the HTTP request like Do and also returns the status,
+// headers and raw body of the response
+func (b *RequestBuilder) DoWithMeta(result interface{}) (*ResponseMeta, error) {
+	resp, raw, err := b.do(result, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = raw
+	return resp, nil
+}
+
+// DoWithRaw executes the HTTP request like Do and also returns the raw body
+// of the 2xx response. The body is read once and decoded from the buffer.
+func (b *RequestBuilder) DoWithRaw(result interface{}) ([]byte, error) {
+	_, raw, err := b.do(result, nil, true)
+	return raw, err
+}
+
+// DoBytes executes the HTTP request and returns the raw body and status code.
+// Non-2xx responses fail like Do, returning the error body along with the
+// *APIError; other errors return a zero status.
+func (b *RequestBuilder) DoBytes() ([]byte, int, error) {
+	var data []byte
+	resp, err := b.DoResponse(&data)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return apiErr.Body, apiErr.StatusCode, err
+		}
+		return nil, 0, err
+	}
+	return data, resp.StatusCode, nil
+}
+
+// DoXML executes the HTTP request like Do but decodes the response as XML
+func (b *RequestBuilder) DoXML(result interface{}) error {
+	_, _, err := b.do(result, XMLCodec, false)
+	return err
+}
+
+// do executes the request, handles error statuses and decodes the body into result.
+// A nil codec selects one from the response Content-Type.
+// The body is buffered and returned when buffer is true or when a success validator needs it.
+func (b *RequestBuilder) do(result interface{}, codec Codec, buffer bool) (*Response, []byte, error) {
 	if b.err != nil {
-		return b.err
+		return nil, nil, b.err
+	}
+	if err := b.consume(); err != nil {
+		return nil, nil, err
 	}
 
 	resp, err := b.execute()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	// Redirects are results in their own right when they are not followed
+	if b.client.noRedirects && isRedirect(resp.StatusCode) {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return newResponse(resp), nil, nil
+	}
+
 	// Handle error responses
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return handleErrorResponse(resp)
+		return nil, nil, b.client.handleErrorResponse(resp)
 	}
 
-	// Parse response if result is provided
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+	var body io.Reader = resp.Body
+	var data []byte
+
+	if buffer || b.client.successValidator != nil || b.schema != nil || b.transform != nil {
+		data, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read response: %w", err)
 		}
+		body = bytes.NewReader(data)
 	}
 
-	return nil
+	// Let the success validator inspect the buffered body before decoding
+	if validate := b.client.successValidator; validate != nil {
+		if err := validate(resp, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if b.schema != nil {
+		if err := b.validateSchema(data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if b.transform != nil {
+		transformed, err := b.transform(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to transform response: %w", err)
+		}
+		body = bytes.NewReader(transformed)
+	}
+
+	// Parse response if result is provided and there is a body to parse
+	if result != nil && !isEmptyResponse(resp) {
+		handled, err := copyRawBody(body, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		if handled {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return newResponse(resp), data, nil
+		}
+
+		if codec == nil {
+			codec = b.client.responseCodec(resp.Header.Get("Content-Type"))
+		}
+		if err := codec.Decode(body, result); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if validate := b.client.resultValidator; validate != nil {
+			if err := validate(result); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// Trailers are only populated once the body has been fully consumed
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return newResponse(resp), data, nil
+}
+
+// DoStream executes the HTTP request and copies the response body into w
+// without buffering it in memory. Error statuses are handled as in Do.
+// Response middleware sees the live body and must restore anything it reads.
+func (b *RequestBuilder) DoStream(w io.Writer) (int64, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if err := b.consume(); err != nil {
+		return 0, err
+	}
+
+	b.streaming = true
+	resp, err := b.execute()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, b.client.handleErrorResponse(resp)
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("failed to stream response: %w", err)
+	}
+	return n, nil
+}
+
+// DoStatus executes the HTTP request, discards the response body and returns
+// the status code. Non-2xx responses return an *APIError along with the code.
+func (b *RequestBuilder) DoStatus() (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if err := b.consume(); err != nil {
+		return 0, err
+	}
+
+	resp, err := b.execute()
+	if err != nil {
+		return 0, err
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, b.client.handleErrorResponse(resp)
+	}
+	return resp.StatusCode, nil
 }
 
 // DoWithResponse executes the HTTP request and returns the raw response
-// This is useful when you need access to response headers or status code
+// This is useful when you need access to response headers or status code.
+// The caller must read the body to EOF and close it so the connection can be reused.
 func (b *RequestBuilder) DoWithResponse() (*http.Response, error) {
 	if b.err != nil {
 		return nil, b.err
 	}
+	if err := b.consume(); err != nil {
+		return nil, err
+	}
 
 	return b.execute()
 }
 
+// BuildRequest assembles the HTTP request and applies the client middleware
+// without sending it. This is useful to inspect what would be sent, such as
+// headers added by auth or signing middleware. The caller owns the request body.
+func (b *RequestBuilder) BuildRequest() (*http.Request, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	req, err := b.buildRequest(b.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
 // execute builds and executes the actual HTTP request
 func (b *RequestBuilder) execute() (*http.Response, error) {
-	// Build full URL by properly joining base URL and path
-	fullURL := joinURL(b.client.baseURL, b.path)
-	if len(b.query) > 0 {
-		fullURL += "?" + b.query.Encode()
+	ctx := b.ctx
+	cancel := context.CancelFunc(func() {})
+	if b.client.totalTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.client.totalTimeout)
+	} else if _, ok := ctx.Deadline(); !ok && b.client.defaultRequestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.client.defaultRequestTimeout)
 	}
 
-	// Create body reader
-	var bodyReader io.Reader
-	if b.body != nil {
-		bodyReader = bytes.NewReader(b.body)
+	start := time.Now()
+	finish := &callFinish{}
+	ctx = context.WithValue(ctx, callStartKey, start)
+	resp, err := b.send(context.WithValue(ctx, callFinishKey, finish))
+	finish.run(resp, err)
+	b.recordMetric(resp, start, err)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
 
-	// Create request
-	req, err := http.NewRequestWithContext(b.ctx, b.method, fullURL, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// Keep the deadline alive until the caller has finished reading the body
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// callFinish holds the functions registered with onCallFinish for a call
+type callFinish struct {
+	fns []func(*http.Response, error)
+}
+
+// onCallFinish registers fn to run once the call carrying ctx completes, with
+// its response or error, whichever stage of the call it failed at. It reports
+// false when ctx belongs to no call, as with BuildRequest.
+func onCallFinish(ctx context.Context, fn func(*http.Response, error)) bool {
+	finish, ok := ctx.Value(callFinishKey).(*callFinish)
+	if !ok {
+		return false
 	}
+	finish.fns = append(finish.fns, fn)
+	return true
+}
 
-	// Set headers
-	for k, v := range b.headers {
-		req.Header.Set(k, v)
+// run calls the registered functions with the outcome of the call
+func (f *callFinish) run(resp *http.Response, err error) {
+	for _, fn := range f.fns {
+		fn(resp, err)
 	}
+}
 
-	// Apply middleware
-	for _, mw := range b.client.middleware {
-		if err := mw(req); err != nil {
-			return nil, fmt.Errorf("middleware error: %w", err)
+// send builds the request with ctx, sends it with retry if configured and applies response middleware
+func (b *RequestBuilder) send(ctx context.Context) (*http.Response, error) {
+	req, err := b.buildRequest(ctx)
+
+	// A middleware may serve the response itself instead of the server
+	resp, skipped := skippedResponse(req, err)
+	if !skipped {
+		if err != nil {
+			return nil, err
+		}
+		resp, err = b.roundTrip(ctx, req)
+		if err != nil {
+			if buf := debugBufferFromContext(req.Context()); buf != nil {
+				_, _ = fmt.Fprintf(&buf.buf, "* Error: %v\n", err)
+				buf.flush()
+			}
+			return nil, err
+		}
+	}
+
+	// Decode compressed responses before middleware sees them
+	if err := decompressResponse(resp, b.client.autoDecompress); err != nil {
+		drainAndClose(resp.Body)
+		return nil, fmt.Errorf("failed to decompress response: %w", err)
+	}
+
+	// Apply response middleware if configured
+	if len(b.client.responseMiddleware) > 0 {
+		if err := b.applyResponseMiddleware(resp); err != nil {
+			drainAndClose(resp.Body)
+			return nil, err
 		}
 	}
 
-	// Execute with retry if configured
+	return resp, nil
+}
+
+// roundTrip sends req, with retry if configured, and runs the start and finish callbacks around it
+func (b *RequestBuilder) roundTrip(ctx context.Context, req *http.Request) (*http.Response, error) {
+	for _, fn := range b.client.onRequestStart {
+		fn(req)
+	}
+	start := time.Now()
+
+	doer := b.client.httpClient
+	if cb := b.client.circuitBreaker; cb != nil {
+		doer = cb.wrap(doer)
+	}
+
 	var resp *http.Response
+	var err error
 	if b.client.retryConfig != nil {
-		resp, err = executeWithRetry(b.ctx, b.client.httpClient, req, b.client.retryConfig)
+		resp, err = executeWithRetry(ctx, doer, req, b.client.retryConfig, b.client.retryRand, b.retryObserver())
 	} else {
-		resp, err = b.client.httpClient.Do(req)
+		resp, err = doer.Do(req)
+	}
+
+	elapsed := time.Since(start)
+	for _, fn := range b.client.onRequestFinish {
+		fn(req, resp, err, elapsed)
+	}
+	return resp, err
+}
+
+// buildRequest assembles the HTTP request with ctx and applies request middleware
+func (b *RequestBuilder) buildRequest(ctx context.Context) (*http.Request, error) {
+	if b.bodyForked && b.body == nil && b.bodySrc == nil {
+		return nil, ErrBodyReaderForked
 	}
 
+	// Build full URL by properly joining base URL and path
+	baseURL := b.client.baseURL
+	if _, abs := absoluteURL(b.path); !abs && b.basePath != nil {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse base URL: %w", err)
+		}
+		u.Path, u.RawPath = *b.basePath, ""
+		baseURL = u.String()
+	}
+	u, err := joinURL(baseURL, expandPathParams(b.path, b.pathParams))
 	if err != nil {
 		return nil, err
 	}
+	if query := b.mergedQuery(); len(query) > 0 {
+		u.RawQuery = joinQuery(u.RawQuery, query.Encode())
+	}
+	fullURL := u.String()
 
-	// Apply response middleware if configured
-	if len(b.client.responseMiddleware) > 0 {
-		if err := b.applyResponseMiddleware(resp); err != nil {
-			_ = resp.Body.Close()
+	// Create body reader
+	var bodyReader io.Reader
+	contentLength := int64(-1)
+	limit := int64(b.client.maxRequestBytes)
+	switch {
+	case b.bodySrc != nil:
+		rc, n, err := b.bodySrc()
+		if err != nil {
+			b.err = err
 			return nil, err
 		}
+		if limit > 0 && n > limit {
+			_ = rc.Close()
+			return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrRequestTooLarge, n, limit)
+		}
+		if limit > 0 {
+			rc = &limitedBody{ReadCloser: rc, remaining: limit}
+		}
+		bodyReader, contentLength = rc, n
+	case b.body != nil:
+		if limit > 0 && int64(len(b.body)) > limit {
+			return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrRequestTooLarge, len(b.body), limit)
+		}
+		payload := b.body
+		if b.gzipLevel != nil {
+			compressed, err := gzipBytes(payload, *b.gzipLevel)
+			if err != nil {
+				return nil, err
+			}
+			payload = compressed
+		}
+		bodyReader = bytes.NewReader(payload)
 	}
 
-	return resp, nil
+	// Tunnel restricted methods through POST if configured
+	method := b.method
+	overridden := b.client.methodOverride && isOverridableMethod(method)
+	if overridden {
+		method = http.MethodPost
+	}
+
+	if b.requestID != "" {
+		ctx = context.WithValue(ctx, requestIDKey, b.requestID)
+	}
+	if len(b.metricLabels) > 0 {
+		ctx = context.WithValue(ctx, metricLabelsKey, b.metricLabels)
+	}
+	if opts := b.client.debugOnError; opts != nil {
+		ctx = context.WithValue(ctx, debugBufferKey, &debugBuffer{w: opts.Writer})
+	}
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
+	if err != nil {
+		if rc, ok := bodyReader.(io.Closer); ok {
+			_ = rc.Close()
+		}
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if b.chunked && req.Body != nil {
+		req.TransferEncoding = []string{"chunked"}
+		req.ContentLength = 0
+		contentLength = -1
+	}
+	if b.bodySrc != nil {
+		if contentLength >= 0 {
+			req.ContentLength = contentLength
+		}
+		// A reader that cannot seek is sent only once
+		if _, seekable := b.bodyReader.(io.Seeker); seekable || b.bodyReader == nil {
+			src := b.bodySrc
+			req.GetBody = func() (io.ReadCloser, error) {
+				rc, _, err := src()
+				if err == nil && limit > 0 {
+					rc = &limitedBody{ReadCloser: rc, remaining: limit}
+				}
+				return rc, err
+			}
+		}
+	}
+
+	// Set headers, with those scoped to the context first so the builder's win
+	for k, v := range ScopedHeadersFromContext(ctx) {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	for k, v := range b.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if overridden {
+		req.Header.Set("X-HTTP-Method-Override", b.method)
+	}
+	if b.requestID != "" {
+		req.Header.Set(RequestIDHeader, b.requestID)
+	}
+	if b.gzipLevel != nil && b.body != nil {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if len(b.client.autoDecompress) > 0 && req.Header.Get("Accept-Encoding") == "" {
+		if accept := acceptEncoding(b.client.autoDecompress); accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+	}
+
+	// Apply middleware. A middleware skipping the request stops the chain and
+	// the request is returned along with the error for send to serve it.
+	for _, mw := range b.client.middleware {
+		if err := mw(req); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			if errors.Is(err, ErrSkipRequest) {
+				return req, err
+			}
+			return nil, fmt.Errorf("middleware error: %w", err)
+		}
+	}
+
+	for k, v := range b.headersIfAbsent {
+		if _, ok := req.Header[k]; !ok {
+			req.Header[k] = append([]string(nil), v...)
+		}
+	}
+
+	return req, nil
 }
 
-// applyResponseMiddleware applies all response middleware to the response.
-// It reads the body once, applies all middleware, and restores the body for downstream use.
-// If any middleware fails, the body is still restored and the error is returned.
+// applyResponseMiddleware applies all response middleware to the response in
+// registration order. It reads the body once, applies all middleware, and restores
+// the body for downstream use. If any middleware fails, the remaining middleware
+// is skipped, the body is still restored and the error is returned.
+// For streaming calls the live body is passed through and middleware that reads it
+// must restore it, as DebugResponseMiddleware does.
 func (b *RequestBuilder) applyResponseMiddleware(resp *http.Response) error {
+	if b.streaming {
+		for _, mw := range b.client.responseMiddleware {
+			if err := mw(resp); err != nil {
+				return fmt.Errorf("response middleware error: %w", err)
+			}
+		}
+		return nil
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		drainAndClose(resp.Body)
 		return fmt.Errorf("failed to read response body for middleware: %w", err)
 	}
 	_ = resp.Body.Close()
 
-	// Restore body so middleware can read it
-	resp.Body = io.NopCloser(bytes.NewReader(body))
-
-	// Apply all middleware
+	// Apply all middleware, each reading the body from the start
 	for _, mw := range b.client.responseMiddleware {
-		if err := mw(resp); err != nil {
+		current := io.NopCloser(bytes.NewReader(body))
+		resp.Body = current
+		err := mw(resp)
+
+		// Keep a body the middleware replaced, such as a cached one
+		if resp.Body != current {
+			replaced, readErr := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if readErr != nil {
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+				return fmt.Errorf("failed to read response body from middleware: %w", readErr)
+			}
+			body = replaced
+		}
+
+		if err != nil {
 			// Ensure body is restored even on error
 			resp.Body = io.NopCloser(bytes.NewReader(body))
 			return fmt.Errorf("response middleware error: %w", err)
 		}
-		// Restore body for next middleware
-		resp.Body = io.NopCloser(bytes.NewReader(body))
 	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
 
 	return nil
 }
 
-// joinURL properly joins base URL and path, handling slashes correctly.
-// It ensures there is exactly one slash between base and path.
-func joinURL(base, p string) string {
+// isEmptyResponse reports whether resp is known to carry no body, leaving a
+// result passed to Do untouched
+func isEmptyResponse(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusNoContent || resp.ContentLength == 0 ||
+		(resp.Request != nil && resp.Request.Method == http.MethodHead)
+}
+
+// copyRawBody copies the body into result without decoding when result is a
+// *[]byte, *string or io.Writer, reporting whether it did so
+func copyRawBody(body io.Reader, result interface{}) (bool, error) {
+	switch dst := result.(type) {
+	case *[]byte:
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return true, fmt.Errorf("failed to read response: %w", err)
+		}
+		*dst = data
+	case *string:
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return true, fmt.Errorf("failed to read response: %w", err)
+		}
+		*dst = string(data)
+	case io.Writer:
+		if _, err := io.Copy(dst, body); err != nil {
+			return true, fmt.Errorf("failed to copy response: %w", err)
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// maxDrainBytes caps how much of an unwanted body is read to allow connection reuse
+const maxDrainBytes = 64 * 1024
+
+// drainAndClose discards what is left of a body and closes it, so the
+// underlying connection can be reused
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	_ = body.Close()
+}
+
+// cancelOnClose releases a request context once the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// limitedBody fails a streamed request body with ErrRequestTooLarge once it
+// yields more than the allowed number of bytes
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read reads from the underlying body, enforcing the byte limit
+func (l *limitedBody) Read(p []byte) (int, error) {
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrRequestTooLarge
+	}
+	return n, err
+}
+
+// expandPathParams replaces {name} placeholders in path with escaped values
+func expandPathParams(path string, params map[string]string) string {
+	for name, value := range params {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+	return path
+}
+
+// isRedirect reports whether a status code is a 3xx redirect
+func isRedirect(statusCode int) bool {
+	return statusCode >= 300 && statusCode < 400
+}
+
+// isOverridableMethod reports whether a method is tunneled by WithMethodOverride
+func isOverridableMethod(method string) bool {
+	switch method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// joinURL resolves path p against the base URL. Paths are relative to the base
+// path even when they start with a slash, so "/users" on "https://host/v1"
+// yields "https://host/v1/users". Query parameters from both are kept, and an
+// absolute URL is used as-is.
+func joinURL(base, p string) (*url.URL, error) {
+	if abs, ok := absoluteURL(p); ok {
+		return abs, nil
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL: %w", err)
+	}
 	if p == "" {
-		return base
+		return u, nil
+	}
+
+	// Resolve against a directory-style base so its last segment is kept,
+	// and anchor the path at that directory rather than the host root
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		if u.RawPath != "" {
+			u.RawPath += "/"
+		}
+	}
+	ref, err := url.Parse("./" + strings.TrimLeft(p, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse path: %w", err)
+	}
+
+	resolved := u.ResolveReference(ref)
+	resolved.RawQuery = joinQuery(u.RawQuery, ref.RawQuery)
+	return resolved, nil
+}
+
+// absoluteURL parses p as an absolute URL such as a pagination link to another
+// host. Only URLs with a scheme and host count; "documents:batchGet" is a path.
+func absoluteURL(p string) (*url.URL, bool) {
+	u, err := url.Parse(p)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// cloneValues returns a deep copy of v, keeping nil as nil
+func cloneValues(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+	c := make(url.Values, len(v))
+	for k, vs := range v {
+		c[k] = append([]string(nil), vs...)
+	}
+	return c
+}
+
+// cloneMap returns a copy of m, keeping nil as nil
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// joinQuery concatenates two encoded query strings
+func joinQuery(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
 	}
-	// Remove trailing slash from base
-	base = strings.TrimSuffix(base, "/")
-	// Remove leading slash from path
-	p = strings.TrimPrefix(p, "/")
-	return base + "/" + p
+	return a + "&" + b
 }
@@ -0,0 +1,660 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequestBuilder_WithFile(t *testing.T) {
+	content := strings.Repeat("file-content-", 1024)
+	path := filepath.Join(t.TempDir(), "upload.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "text/plain" {
+			t.Errorf("Expected Content-Type text/plain, got %s", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != content {
+			t.Errorf("Expected %d bytes of file content, got %d bytes", len(content), len(body))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.PUT("/api/v1/upload").
+		WithFile(path, "text/plain").
+		Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestRequestBuilder_WithFile_KeepsContentType(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.json")
+	if err := os.WriteFile(path, []byte(`{"id":"1"}`), 0o600); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	req, err := client.PUT("/api/v1/upload").
+		WithContentType("application/vnd.api+json").
+		WithFile(path, "application/json").
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+	defer func() { _ = req.Body.Close() }()
+
+	if ct := req.Header.Get("Content-Type"); ct != "application/vnd.api+json" {
+		t.Errorf("Expected Content-Type application/vnd.api+json, got %s", ct)
+	}
+}
+
+func TestRequestBuilder_WithFile_Missing(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "http://127.0.0.1:0",
+		Timeout: 5 * time.Second,
+	})
+
+	builder := client.PUT("/api/v1/upload").
+		WithFile(filepath.Join(t.TempDir(), "missing.txt"), "text/plain")
+	if err := builder.Do(nil); err == nil {
+		t.Fatal("Expected error for missing file")
+	}
+	if builder.err == nil {
+		t.Error("Expected open error to be captured on the builder")
+	}
+}
+
+func TestRequestBuilder_WithBodyReader(t *testing.T) {
+	content := strings.Repeat("streamed-content-", 4096)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != content {
+			t.Errorf("Expected %d bytes of streamed content, got %d bytes", len(content), len(body))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < len(content); i += 1000 {
+			end := min(i+1000, len(content))
+			if _, err := pw.Write([]byte(content[i:end])); err != nil {
+				return
+			}
+		}
+		_ = pw.Close()
+	}()
+
+	err := client.PUT("/api/v1/upload").
+		WithBodyReader(pr).
+		Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestRequestBuilder_WithBodyReader_Retry(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("Expected body 'payload' on attempt %d, got '%s'", attempts, body)
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetry(3, time.Millisecond, 10*time.Millisecond))
+
+	// A seekable reader is rewound to its starting offset for the retry
+	reader := strings.NewReader("skip:payload")
+	_, _ = reader.Seek(5, io.SeekStart)
+	if err := client.POST("/api/v1/upload").WithBodyReader(reader).Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+
+	// A reader that cannot be replayed is sent once
+	attempts = 0
+	err := client.POST("/api/v1/upload").
+		WithBodyReader(io.MultiReader(strings.NewReader("payload"))).
+		Do(nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 APIError, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRequestBuilder_WithBodyReader_Fork(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	base := client.POST("/api/v1/upload").
+		WithHeader("X-Tenant", "acme").
+		WithBodyReader(strings.NewReader("shared"))
+
+	// A fork cannot share the reader
+	if err := base.Fork().Do(nil); !errors.Is(err, ErrBodyReaderForked) {
+		t.Errorf("Expected ErrBodyReaderForked, got %v", err)
+	}
+
+	// A fork with a body of its own is sent
+	if err := base.Fork().WithBodyReader(strings.NewReader("own")).Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	// The original keeps its reader
+	if err := base.Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if strings.Join(bodies, ",") != "own,shared" {
+		t.Errorf("Expected bodies own,shared, got %v", bodies)
+	}
+}
+
+func TestRequestBuilder_BuildRequest(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(AuthMiddleware("Bearer", "test-token")))
+
+	req, err := client.GET("/api/v1/test").
+		WithQuery("page", "1").
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer test-token" {
+		t.Errorf("Expected Authorization 'Bearer test-token', got '%s'", got)
+	}
+	if got := req.URL.String(); got != "https://api.example.com/api/v1/test?page=1" {
+		t.Errorf("Unexpected URL %s", got)
+	}
+}
+
+func TestRequestBuilder_WithChunked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TransferEncoding) == 0 || r.TransferEncoding[0] != "chunked" {
+			w.WriteHeader(http.StatusLengthRequired)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "chunked-payload" {
+			t.Errorf("Expected body 'chunked-payload', got '%s'", body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.POST("/api/v1/upload").
+		WithBody([]byte("chunked-payload")).
+		WithChunked().
+		Do(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestRequestBuilder_MaxRequestBytes(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMaxRequestBytes(10))
+
+	err := client.POST("/api/v1/upload").
+		WithBody([]byte("this body is too large")).
+		Do(nil)
+	if !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("Expected ErrRequestTooLarge, got %v", err)
+	}
+	if hits != 0 {
+		t.Errorf("Expected oversized request not to be sent, got %d requests", hits)
+	}
+
+	if err := client.POST("/api/v1/upload").WithBody([]byte("small")).Do(nil); err != nil {
+		t.Errorf("Request within limit failed: %v", err)
+	}
+}
+
+func TestRequestBuilder_When(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	addTrace := func(b *RequestBuilder) *RequestBuilder {
+		return b.WithHeader("X-Trace", "on")
+	}
+
+	for _, enabled := range []bool{true, false} {
+		req, err := client.GET("/api/v1/test").When(enabled, addTrace).BuildRequest()
+		if err != nil {
+			t.Fatalf("BuildRequest failed: %v", err)
+		}
+		if got := req.Header.Get("X-Trace") == "on"; got != enabled {
+			t.Errorf("With flag %v, expected header present=%v, got %v", enabled, enabled, got)
+		}
+	}
+}
+
+func TestRequestBuilder_ConnectionReuseAfterMiddlewareError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 4096)))
+	}))
+	defer server.Close()
+
+	failing := true
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithResponseMiddleware(func(*http.Response) error {
+		if failing {
+			return errors.New("rejected")
+		}
+		return nil
+	}))
+
+	if err := client.GET("/api/v1/test").Do(nil); err == nil {
+		t.Fatal("Expected response middleware error")
+	}
+
+	failing = false
+	var reused bool
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused },
+	}
+	ctx := httptrace.WithClientTrace(context.Background(), trace)
+	if err := client.GET("/api/v1/test").WithContext(ctx).Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !reused {
+		t.Error("Expected connection to be reused after middleware error")
+	}
+}
+
+func TestRequestBuilder_WithBasePath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/users" {
+			t.Errorf("Expected path /v2/users, got %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL + "/v1",
+		Timeout: 5 * time.Second,
+	})
+
+	if err := client.GET("/users").WithBasePath("/v2").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestRequestBuilder_WithPathParams(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	req, err := client.GET("/orgs/{org}/repos/{repo}/issues/{number}").
+		WithPathParams(map[string]string{"org": "acme corp", "repo": "a/b"}).
+		WithPathParam("number", "42").
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+
+	expected := "https://api.example.com/orgs/acme%20corp/repos/a%2Fb/issues/42"
+	if got := req.URL.String(); got != expected {
+		t.Errorf("Expected URL %s, got %s", expected, got)
+	}
+}
+
+func TestRequestBuilder_WithHeaderIfAbsent(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(HeaderMiddleware(map[string]string{"Content-Type": "application/vnd.api+json"})))
+
+	req, err := client.POST("/api/v1/items").
+		WithHeaderIfAbsent("Content-Type", "application/json").
+		WithHeaderIfAbsent("X-Client", "httpclient").
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+
+	if got := req.Header.Get("Content-Type"); got != "application/vnd.api+json" {
+		t.Errorf("Expected middleware Content-Type to win, got '%s'", got)
+	}
+	if got := req.Header.Get("X-Client"); got != "httpclient" {
+		t.Errorf("Expected absent header to be set, got '%s'", got)
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		path     string
+		expected string
+	}{
+		{"base without path", "https://api.example.com", "/users", "https://api.example.com/users"},
+		{"base with path", "https://api.example.com/v1", "/users", "https://api.example.com/v1/users"},
+		{"base with trailing slash", "https://api.example.com/api/v1/", "users", "https://api.example.com/api/v1/users"},
+		{"absolute path under base path", "https://api.example.com/api/v1/", "/users/42", "https://api.example.com/api/v1/users/42"},
+		{"base with query", "https://api.example.com/v1?key=abc", "/users", "https://api.example.com/v1/users?key=abc"},
+		{"path with query", "https://api.example.com/v1?key=abc", "/users?page=2", "https://api.example.com/v1/users?key=abc&page=2"},
+		{"path with colon", "https://api.example.com/v1", "documents:batchGet", "https://api.example.com/v1/documents:batchGet"},
+		{"escaped segment", "https://api.example.com", "/repos/a%2Fb", "https://api.example.com/repos/a%2Fb"},
+		{"absolute URL", "https://api.example.com/v1", "https://other.example.com/x", "https://other.example.com/x"},
+		{"empty path", "https://api.example.com/v1", "", "https://api.example.com/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := joinURL(tt.base, tt.path)
+			if err != nil {
+				t.Fatalf("joinURL failed: %v", err)
+			}
+			if got := u.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRequestBuilder_Fork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Tenant") + " " + r.URL.Query().Encode()))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	base := client.GET("/search").
+		WithHeader("X-Tenant", "acme").
+		WithQuery("limit", "10")
+
+	var wg sync.WaitGroup
+	results := make([]string, 2)
+	errs := make([]error, 2)
+	for i, q := range []string{"foo", "bar"} {
+		wg.Add(1)
+		go func(i int, q string) {
+			defer wg.Done()
+			errs[i] = base.Fork().WithQuery("q", q).Do(&results[i])
+		}(i, q)
+	}
+	wg.Wait()
+
+	for i, expected := range []string{"acme limit=10&q=foo", "acme limit=10&q=bar"} {
+		if errs[i] != nil {
+			t.Fatalf("Request %d failed: %v", i, errs[i])
+		}
+		if results[i] != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, results[i])
+		}
+	}
+
+	if q := base.query.Get("q"); q != "" {
+		t.Errorf("Expected shared builder to be unchanged, got q=%s", q)
+	}
+}
+
+func TestRequestBuilder_Consumed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	req := client.GET("/ping")
+	if err := req.Do(nil); err != nil {
+		t.Fatalf("First request failed: %v", err)
+	}
+	if err := req.Do(nil); !errors.Is(err, ErrBuilderConsumed) {
+		t.Errorf("Expected ErrBuilderConsumed, got %v", err)
+	}
+	if _, err := req.DoStatus(); !errors.Is(err, ErrBuilderConsumed) {
+		t.Errorf("Expected ErrBuilderConsumed from DoStatus, got %v", err)
+	}
+}
+
+func TestRequestBuilder_WithContentType(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	req, err := client.POST("/articles").
+		WithContentType("application/vnd.api+json").
+		WithJSON(map[string]string{"title": "Hello"}).
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/vnd.api+json" {
+		t.Errorf("Expected explicit Content-Type to survive WithJSON, got '%s'", got)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"title":"Hello"}` {
+		t.Errorf("Expected JSON body, got %s", body)
+	}
+
+	req, err = client.POST("/login").
+		WithContentType("application/x-www-form-urlencoded; charset=utf-8").
+		WithFormMap(map[string]string{"user": "john"}).
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded; charset=utf-8" {
+		t.Errorf("Expected explicit Content-Type to survive WithForm, got '%s'", got)
+	}
+
+	req, err = client.POST("/articles").
+		WithJSON(map[string]string{"title": "Hello"}).
+		WithContentType("application/merge-patch+json").
+		BuildRequest()
+	if err != nil {
+		t.Fatalf("BuildRequest failed: %v", err)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/merge-patch+json" {
+		t.Errorf("Expected WithContentType after WithJSON to win, got '%s'", got)
+	}
+}
+
+func TestResponseMiddleware_Order(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	var order []string
+	appendMarker := func(marker string) ResponseMiddleware {
+		return func(resp *http.Response) error {
+			order = append(order, marker)
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			resp.Body = io.NopCloser(strings.NewReader(string(body) + "-" + marker))
+			return nil
+		}
+	}
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithResponseMiddleware(appendMarker("first")),
+		WithResponseMiddleware(appendMarker("second")))
+
+	var body string
+	if err := client.GET("/api/v1/test").Do(&body); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if strings.Join(order, ",") != "first,second" {
+		t.Errorf("Expected middleware order first,second, got %v", order)
+	}
+	if body != "body-first-second" {
+		t.Errorf("Expected body 'body-first-second', got '%s'", body)
+	}
+}
+
+func TestResponseMiddleware_ErrorStopsChain(t *testing.T) {
+	var secondRan bool
+	client := NewClient(&Config{
+		BaseURL: "http://example.com",
+		Timeout: 5 * time.Second,
+	}, WithResponseMiddleware(func(resp *http.Response) error {
+		_, _ = io.ReadAll(resp.Body)
+		return errors.New("rejected")
+	}), WithResponseMiddleware(func(*http.Response) error {
+		secondRan = true
+		return nil
+	}))
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("payload")),
+	}
+	err := client.GET("/api/v1/test").applyResponseMiddleware(resp)
+	if err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Fatalf("Expected middleware error, got %v", err)
+	}
+	if secondRan {
+		t.Error("Expected second middleware not to run after the first failed")
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "payload" {
+		t.Errorf("Expected body 'payload' to be restored, got '%s'", body)
+	}
+}
+
+func TestRequestBuilder_WithResponseTransform(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"id": "123", "name": "John"}, "meta": {"version": 2}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	unwrap := func(body []byte) ([]byte, error) {
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return nil, err
+		}
+		return envelope.Data, nil
+	}
+
+	var user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	raw, err := client.GET("/users/123").
+		WithResponseTransform(unwrap).
+		DoWithRaw(&user)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if user.ID != "123" || user.Name != "John" {
+		t.Errorf("Expected unwrapped user, got %+v", user)
+	}
+	if !strings.Contains(string(raw), `"meta"`) {
+		t.Errorf("Expected raw body to be returned as received, got %s", raw)
+	}
+
+	err = client.GET("/users/123").
+		WithResponseTransform(func([]byte) ([]byte, error) { return nil, errors.New("boom") }).
+		Do(&user)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected transform error, got %v", err)
+	}
+}
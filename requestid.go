@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to send request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// contextKey is the type for context keys owned by this package
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	metricLabelsKey
+	scopedHeadersKey
+	debugBufferKey
+	callStartKey
+	retryOutcomesKey
+	callFinishKey
+)
+
+// RequestIDFromContext returns the request ID attached by WithRequestID or WithRequestIDValue
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok && id != ""
+}
+
+// newRequestID generates a random UUID (version 4)
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// WithRequestID generates a request ID for this call.
+// See WithRequestIDValue for how the ID is used.
+func (b *RequestBuilder) WithRequestID() *RequestBuilder {
+	return b.WithRequestIDValue(newRequestID())
+}
+
+// WithRequestIDValue sets the request ID for this call. The ID is sent in the
+// X-Request-ID header, attached to the request context, and reported on any
+// APIError and in debug output for the call.
+func (b *RequestBuilder) WithRequestIDValue(id string) *RequestBuilder {
+	b.requestID = id
+	return b
+}
+
+// RequestIDMiddleware returns a middleware that sets header to an ID from gen
+// on every request that does not already carry one, such as from WithHeader or
+// WithRequestIDValue. An empty header defaults to RequestIDHeader and a nil gen
+// to random UUIDs. Generated IDs are also reported like those of WithRequestID.
+//
+// Example usage:
+//
+//	client := httpclient.NewClient(config,
+//	    httpclient.WithMiddleware(httpclient.RequestIDMiddleware("", nil)))
+func RequestIDMiddleware(header string, gen func() string) Middleware {
+	if header == "" {
+		header = RequestIDHeader
+	}
+	if gen == nil {
+		gen = newRequestID
+	}
+
+	return func(req *http.Request) error {
+		if req.Header.Get(header) != "" {
+			return nil
+		}
+		id := gen()
+		req.Header.Set(header, id)
+		if _, ok := RequestIDFromContext(req.Context()); !ok {
+			*req = *req.WithContext(context.WithValue(req.Context(), requestIDKey, id))
+		}
+		return nil
+	}
+}
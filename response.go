@@ -0,0 +1,31 @@
+package httpclient
+
+import "net/http"
+
+// Response holds the metadata of a response whose body was consumed by the client
+type Response struct {
+	StatusCode int
+	Header     http.Header
+
+	// Trailers holds the HTTP trailers sent after the body
+	Trailers http.Header
+
+	// Location holds the redirect target of a 3xx response when redirects are not followed
+	Location string
+
+	// Body holds the raw response body, set by DoWithMeta
+	Body []byte
+}
+
+// ResponseMeta is the response metadata returned by DoWithMeta
+type ResponseMeta = Response
+
+// newResponse captures the metadata of a fully consumed response
+func newResponse(resp *http.Response) *Response {
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Trailers:   resp.Trailer,
+		Location:   resp.Header.Get("Location"),
+	}
+}
@@ -2,10 +2,14 @@ package httpclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,6 +21,17 @@ type RetryConfig struct {
 	MaxWaitTime time.Duration
 	// ShouldRetry is an optional function to determine if a request should be retried
 	ShouldRetry func(*http.Response, error) bool
+	// OnRetry is an optional function called before waiting to retry, with the
+	// number of the attempt that failed (starting at 1) and its response or error.
+	// The response body is closed once OnRetry returns.
+	OnRetry func(attempt int, resp *http.Response, err error)
+	// Backoff is an optional function returning the wait before retrying after
+	// the given attempt (starting at 0). It defaults to exponential backoff with
+	// jitter. A Retry-After header still takes precedence.
+	Backoff func(attempt int, cfg *RetryConfig) time.Duration
+	// MaxElapsedTime optionally bounds the time spent retrying. Once waiting for
+	// the next attempt would exceed it, the last response or error is returned.
+	MaxElapsedTime time.Duration
 }
 
 // Default retry configuration
@@ -29,41 +44,134 @@ var (
 // executeWithRetry executes an HTTP request with exponential backoff retry
 // Implements exponential backoff with jitter based on AWS best practices
 // Reference: https://amazonaws-china.com/cn/blogs/architecture/exponential-backoff-and-jitter/
-func executeWithRetry(ctx context.Context, client Doer, req *http.Request, config *RetryConfig) (*http.Response, error) {
+// observe, if not nil, is called for every retried attempt and once more if retries run out.
+func executeWithRetry(ctx context.Context, client Doer, req *http.Request, config *RetryConfig, rng *lockedRand, observe retryObserver) (*http.Response, error) {
 	applyRetryDefaults(config)
 
 	var lastErr error
 	var resp *http.Response
+	outcomes := make([]string, 0, config.MaxAttempts)
+	start := time.Now()
 
 	for attempt := 0; attempt < config.MaxAttempts; attempt++ {
+		// The previous attempt consumed the body, so send a fresh copy
+		if attempt > 0 {
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+		}
+
 		resp, lastErr = client.Do(req)
+		outcomes = append(outcomes, describeAttempt(resp, lastErr))
+
+		// An open circuit breaker fails every attempt, so stop right away
+		if errors.Is(lastErr, ErrCircuitOpen) {
+			break
+		}
 
 		shouldRetry := defaultShouldRetry(resp, lastErr)
 		if config.ShouldRetry != nil {
 			shouldRetry = config.ShouldRetry(resp, lastErr)
 		}
 
-		if !shouldRetry {
-			return resp, lastErr
+		// Return the final attempt as is so its body can still be read.
+		// A streamed body that cannot be replayed is only sent once.
+		if !shouldRetry || !canRewindBody(req) {
+			break
+		}
+
+		var wait time.Duration
+		if config.Backoff != nil {
+			wait = config.Backoff(attempt, config)
+		} else {
+			wait = calculateBackoff(attempt, config.WaitTime, config.MaxWaitTime, rng)
+		}
+		if d, ok := retryAfter(resp, time.Now()); ok {
+			wait = min(d, config.MaxWaitTime)
+		}
+
+		// Give up when out of attempts or when waiting would exceed MaxElapsedTime
+		if attempt == config.MaxAttempts-1 ||
+			(config.MaxElapsedTime > 0 && time.Since(start)+wait > config.MaxElapsedTime) {
+			if observe != nil {
+				observe(resp, lastErr, true)
+			}
+			break
+		}
+		if observe != nil {
+			observe(resp, lastErr, false)
+		}
+
+		if config.OnRetry != nil {
+			config.OnRetry(attempt+1, resp, lastErr)
 		}
 
 		if resp != nil {
 			_ = resp.Body.Close()
 		}
 
-		if attempt < config.MaxAttempts-1 {
-			if err := waitWithBackoff(ctx, attempt, config); err != nil {
-				return nil, err
-			}
+		if err := waitWithBackoff(ctx, wait); err != nil {
+			return nil, err
 		}
 	}
 
 	if lastErr != nil {
-		return nil, fmt.Errorf("request failed after %d attempts: %w", config.MaxAttempts, lastErr)
+		if len(outcomes) == 1 {
+			return nil, lastErr
+		}
+		return nil, fmt.Errorf("request failed after %d attempts [%s]: %w",
+			len(outcomes), strings.Join(outcomes, ", "), lastErr)
+	}
+
+	// Let a final error response report the earlier attempts too
+	if len(outcomes) > 1 && resp.Request != nil {
+		resp.Request = resp.Request.WithContext(context.WithValue(resp.Request.Context(), retryOutcomesKey, outcomes))
 	}
 	return resp, nil
 }
 
+// retryObserver is notified of a retried attempt, or with exhausted set, of a
+// final attempt that would have been retried had attempts remained
+type retryObserver func(resp *http.Response, err error, exhausted bool)
+
+// rewindBody replaces a consumed request body with a fresh one from GetBody
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	req.Body = body
+	return nil
+}
+
+// canRewindBody reports whether req can be sent again, having no body or one that GetBody can replay
+func canRewindBody(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// describeAttempt summarizes the outcome of an attempt as a status code or error kind
+func describeAttempt(resp *http.Response, err error) string {
+	if err != nil {
+		switch {
+		case IsTimeout(err):
+			return "timeout"
+		case IsCanceled(err):
+			return "canceled"
+		case errors.Is(err, ErrCircuitOpen):
+			return "circuit open"
+		default:
+			return "error"
+		}
+	}
+	if resp == nil {
+		return "no response"
+	}
+	return strconv.Itoa(resp.StatusCode)
+}
+
 // applyRetryDefaults applies default values to retry configuration
 func applyRetryDefaults(config *RetryConfig) {
 	if config.MaxAttempts == 0 {
@@ -77,9 +185,8 @@ func applyRetryDefaults(config *RetryConfig) {
 	}
 }
 
-// waitWithBackoff waits for the calculated backoff duration with context support
-func waitWithBackoff(ctx context.Context, attempt int, config *RetryConfig) error {
-	backoff := calculateBackoff(attempt, config.WaitTime, config.MaxWaitTime)
+// waitWithBackoff waits for the backoff duration with context support
+func waitWithBackoff(ctx context.Context, backoff time.Duration) error {
 	timer := time.NewTimer(backoff)
 	defer timer.Stop()
 
@@ -91,8 +198,38 @@ func waitWithBackoff(ctx context.Context, attempt int, config *RetryConfig) erro
 	}
 }
 
+// retryAfter returns the delay requested by the Retry-After header of a 429 or 503
+// response, given in seconds or as an HTTP date relative to now
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil ||
+		(resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	return parseRetryAfter(resp.Header.Get("Retry-After"), now)
+}
+
+// parseRetryAfter parses a Retry-After value given in seconds or as an HTTP
+// date relative to now
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		seconds = min(seconds, math.MaxInt32)
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
 // defaultShouldRetry determines if a request should be retried
-// Retry on network errors or 5xx server errors
+// Retry on network errors or 5xx server errors other than 501, 505 and 511
 func defaultShouldRetry(resp *http.Response, err error) bool {
 	// Network error - retry
 	if err != nil {
@@ -104,9 +241,9 @@ func defaultShouldRetry(resp *http.Response, err error) bool {
 		return true
 	}
 
-	// Server errors (5xx) - retry
+	// Server errors (5xx) - retry, except those that won't change on retry
 	if resp.StatusCode >= 500 {
-		return true
+		return !isPermanentServerError(resp.StatusCode)
 	}
 
 	// Too Many Requests (429) - retry
@@ -118,9 +255,22 @@ func defaultShouldRetry(resp *http.Response, err error) bool {
 	return false
 }
 
+// isPermanentServerError reports whether a 5xx status describes a condition
+// that retrying the same request cannot fix
+func isPermanentServerError(statusCode int) bool {
+	switch statusCode {
+	case http.StatusNotImplemented,
+		http.StatusHTTPVersionNotSupported,
+		http.StatusNetworkAuthenticationRequired:
+		return true
+	}
+	return false
+}
+
 // calculateBackoff calculates exponential backoff with jitter
 // Formula: min(maxWaitTime, waitTime * 2^attempt) + random jitter
-func calculateBackoff(attempt int, waitTime, maxWaitTime time.Duration) time.Duration {
+// Jitter is drawn from rng, or from the global math/rand source if rng is nil
+func calculateBackoff(attempt int, waitTime, maxWaitTime time.Duration, rng *lockedRand) time.Duration {
 	// Calculate exponential backoff
 	backoff := waitTime * time.Duration(math.Pow(2, float64(attempt)))
 
@@ -132,8 +282,43 @@ func calculateBackoff(attempt int, waitTime, maxWaitTime time.Duration) time.Dur
 	// Add jitter (50% to 100% of backoff)
 	jitter := backoff / 2
 	if jitter > 0 {
-		jitter = time.Duration(rand.Int63n(int64(jitter)))
+		jitter = time.Duration(rng.Int63n(int64(jitter)))
 	}
 
 	return backoff/2 + jitter
 }
+
+// ConstantBackoff waits WaitTime before every retry
+func ConstantBackoff(_ int, cfg *RetryConfig) time.Duration {
+	return cfg.WaitTime
+}
+
+// LinearBackoff waits WaitTime times the number of the attempt, capped at MaxWaitTime
+func LinearBackoff(attempt int, cfg *RetryConfig) time.Duration {
+	backoff := cfg.WaitTime * time.Duration(attempt+1)
+	if cfg.MaxWaitTime > 0 && backoff > cfg.MaxWaitTime {
+		backoff = cfg.MaxWaitTime
+	}
+	return backoff
+}
+
+// lockedRand is a math/rand source safe for concurrent use by retrying requests
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// newLockedRand creates a lockedRand seeded with seed
+func newLockedRand(seed int64) *lockedRand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+// Int63n returns a random number in [0, n), falling back to the global source for a nil receiver
+func (l *lockedRand) Int63n(n int64) int64 {
+	if l == nil {
+		return rand.Int63n(n)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63n(n)
+}
@@ -0,0 +1,458 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry_PermanentServerErrors(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		expectedAttempts int32
+	}{
+		{"501 not retried", http.StatusNotImplemented, 1},
+		{"503 retried", http.StatusServiceUnavailable, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(&Config{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			}, WithRetry(3, time.Millisecond, 5*time.Millisecond))
+
+			if err := client.GET("/api/v1/test").Do(nil); err == nil {
+				t.Fatal("Expected error response")
+			}
+
+			if got := atomic.LoadInt32(&attempts); got != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedAttempts, got)
+			}
+		})
+	}
+}
+
+func TestRetry_JitterSeed(t *testing.T) {
+	c1 := NewClient(nil, WithRetryJitterSeed(42)).(*HTTPClient)
+	c2 := NewClient(nil, WithRetryJitterSeed(42)).(*HTTPClient)
+
+	for attempt := 0; attempt < 5; attempt++ {
+		b1 := calculateBackoff(attempt, 100*time.Millisecond, 10*time.Second, c1.retryRand)
+		b2 := calculateBackoff(attempt, 100*time.Millisecond, 10*time.Second, c2.retryRand)
+		if b1 != b2 {
+			t.Errorf("Attempt %d: expected identical backoff, got %v and %v", attempt, b1, b2)
+		}
+	}
+}
+
+func TestRetry_WithTotalTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	},
+		WithRetry(10, 100*time.Millisecond, time.Second),
+		WithTotalTimeout(150*time.Millisecond),
+	)
+
+	start := time.Now()
+	err := client.GET("/api/v1/test").Do(nil)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Expected call to abort near the total timeout, took %v", elapsed)
+	}
+}
+
+func TestRetry_AttemptBoundedByContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetry(3, 10*time.Millisecond, 50*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := client.GET("/api/v1/test").WithContext(ctx).Do(nil)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Expected attempt to abort near the 100ms deadline, took %v", elapsed)
+	}
+}
+
+func TestRetry_FinalErrorListsAttemptOutcomes(t *testing.T) {
+	var attempts int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 50 * time.Millisecond,
+	}, WithRetry(3, time.Millisecond, 5*time.Millisecond))
+
+	err := client.GET("/api/v1/test").Do(nil)
+	if err == nil {
+		t.Fatal("Expected error after all attempts failed")
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts [503, 503, timeout]") {
+		t.Errorf("Expected per-attempt summary in error, got: %v", err)
+	}
+}
+
+func TestRetry_FinalErrorResponseListsAttemptOutcomes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetry(3, time.Millisecond, 5*time.Millisecond))
+
+	err := client.GET("/api/v1/test").Do(nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if fmt.Sprint(apiErr.Attempts) != "[503 503 503]" {
+		t.Errorf("Expected attempts [503 503 503], got %v", apiErr.Attempts)
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts [503, 503, 503]") {
+		t.Errorf("Expected per-attempt summary in error, got: %v", err)
+	}
+}
+
+func TestRetry_FinalErrorResponseIsReadable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"message":"maintenance"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetry(2, time.Millisecond, 5*time.Millisecond))
+
+	err := client.GET("/api/v1/test").Do(nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.Message != "maintenance" {
+		t.Errorf("Expected message 'maintenance', got '%s'", apiErr.Message)
+	}
+}
+
+func TestRetry_RetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		status   int
+		header   string
+		expected time.Duration
+		ok       bool
+	}{
+		{"seconds on 429", http.StatusTooManyRequests, "3", 3 * time.Second, true},
+		{"seconds on 503", http.StatusServiceUnavailable, "120", 2 * time.Minute, true},
+		{"http date", http.StatusServiceUnavailable, now.Add(5 * time.Second).Format(http.TimeFormat), 5 * time.Second, true},
+		{"http date in the past", http.StatusTooManyRequests, now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"ignored on 500", http.StatusInternalServerError, "3", 0, false},
+		{"missing header", http.StatusTooManyRequests, "", 0, false},
+		{"invalid value", http.StatusTooManyRequests, "soon", 0, false},
+		{"negative seconds", http.StatusTooManyRequests, "-1", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+
+			d, ok := retryAfter(resp, now)
+			if ok != tt.ok || d != tt.expected {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.expected, tt.ok, d, ok)
+			}
+		})
+	}
+}
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetry(2, time.Millisecond, 5*time.Second))
+
+	start := time.Now()
+	if err := client.GET("/api/v1/test").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("Expected to wait for Retry-After, retried after %v", elapsed)
+	}
+}
+
+func TestRetry_RetryAfterCappedAtMaxWaitTime(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetry(2, time.Millisecond, 20*time.Millisecond))
+
+	start := time.Now()
+	if err := client.GET("/api/v1/test").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Retry-After to be capped at MaxWaitTime, took %v", elapsed)
+	}
+}
+
+func TestRetry_ResendsRequestBody(t *testing.T) {
+	// A Doer that reads the body itself, like most mocks and wrappers, unlike
+	// *http.Client which can rewind bodies on its own
+	var bodies []string
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		bodies = append(bodies, string(data))
+
+		status := http.StatusCreated
+		if len(bodies) == 1 {
+			status = http.StatusInternalServerError
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}, WithHTTPClient(doer), WithRetry(3, time.Millisecond, 5*time.Millisecond))
+
+	payload := map[string]string{"name": "John", "email": "john@example.com"}
+	if err := client.POST("/api/v1/users").WithJSON(payload).Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(bodies))
+	}
+	if bodies[0] == "" || bodies[1] != bodies[0] {
+		t.Errorf("Expected identical bodies on both attempts, got %q and %q", bodies[0], bodies[1])
+	}
+}
+
+func TestRetry_OnRetry(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var calls []int
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetryConfig(RetryConfig{
+		MaxAttempts: 4,
+		WaitTime:    time.Millisecond,
+		MaxWaitTime: 5 * time.Millisecond,
+		OnRetry: func(attempt int, resp *http.Response, err error) {
+			if err != nil || resp == nil || resp.StatusCode != http.StatusBadGateway {
+				t.Errorf("Expected 502 response for attempt %d, got %v (err %v)", attempt, resp, err)
+			}
+			calls = append(calls, attempt)
+		},
+	}))
+
+	if err := client.GET("/api/v1/test").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	expected := []int{1, 2, 3}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected %d OnRetry calls, got %d", len(expected), len(calls))
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected attempt %d at call %d, got %d", expected[i], i, calls[i])
+		}
+	}
+}
+
+func TestRetry_BackoffStrategies(t *testing.T) {
+	cfg := &RetryConfig{WaitTime: 100 * time.Millisecond, MaxWaitTime: 350 * time.Millisecond}
+
+	tests := []struct {
+		name     string
+		backoff  func(int, *RetryConfig) time.Duration
+		expected []time.Duration
+	}{
+		{"constant", ConstantBackoff, []time.Duration{100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond}},
+		{"linear", LinearBackoff, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 350 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for attempt, expected := range tt.expected {
+				if got := tt.backoff(attempt, cfg); got != expected {
+					t.Errorf("Attempt %d: expected %v, got %v", attempt, expected, got)
+				}
+			}
+		})
+	}
+
+	t.Run("exponential default", func(t *testing.T) {
+		for attempt, ceiling := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 350 * time.Millisecond, 350 * time.Millisecond} {
+			got := calculateBackoff(attempt, cfg.WaitTime, cfg.MaxWaitTime, nil)
+			if got < ceiling/2 || got > ceiling {
+				t.Errorf("Attempt %d: expected between %v and %v, got %v", attempt, ceiling/2, ceiling, got)
+			}
+		}
+	})
+}
+
+func TestRetry_CustomBackoff(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var calls []int
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetryConfig(RetryConfig{
+		MaxAttempts: 3,
+		WaitTime:    time.Millisecond,
+		Backoff: func(attempt int, cfg *RetryConfig) time.Duration {
+			calls = append(calls, attempt)
+			return ConstantBackoff(attempt, cfg)
+		},
+	}))
+
+	if err := client.GET("/api/v1/test").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if len(calls) != 2 || calls[0] != 0 || calls[1] != 1 {
+		t.Errorf("Expected Backoff to be called for attempts [0 1], got %v", calls)
+	}
+}
+
+func TestRetry_MaxElapsedTime(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithRetryConfig(RetryConfig{
+		MaxAttempts:    10,
+		WaitTime:       40 * time.Millisecond,
+		Backoff:        ConstantBackoff,
+		MaxElapsedTime: 100 * time.Millisecond,
+	}))
+
+	start := time.Now()
+	err := client.GET("/api/v1/test").Do(nil)
+	elapsed := time.Since(start)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the last 503 to be returned, got %v", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n < 2 || n > 3 {
+		t.Errorf("Expected 2-3 attempts within MaxElapsedTime, got %d", n)
+	}
+	if elapsed > 100*time.Millisecond+50*time.Millisecond {
+		t.Errorf("Expected to give up within MaxElapsedTime, took %v", elapsed)
+	}
+}
@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// responseSchemaURL identifies schemas passed to ExpectSchema in validation errors
+const responseSchemaURL = "mem://response-schema.json"
+
+// ExpectSchema validates the 2xx response body against a JSON Schema before
+// it is decoded, failing the call with a descriptive error on mismatch. This
+// catches API contract drift early. The schema is compiled immediately; an
+// invalid schema fails the call without sending it.
+func (b *RequestBuilder) ExpectSchema(schema []byte) *RequestBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(responseSchemaURL, bytes.NewReader(schema)); err != nil {
+		b.err = fmt.Errorf("failed to load response schema: %w", err)
+		return b
+	}
+	compiled, err := compiler.Compile(responseSchemaURL)
+	if err != nil {
+		b.err = fmt.Errorf("failed to compile response schema: %w", err)
+		return b
+	}
+
+	b.schema = compiled
+	return b
+}
+
+// validateSchema checks a buffered response body against the expected schema
+func (b *RequestBuilder) validateSchema(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("response does not match schema: invalid JSON: %w", err)
+	}
+	if err := b.schema.Validate(doc); err != nil {
+		return fmt.Errorf("response does not match schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["id", "email"],
+	"properties": {
+		"id": {"type": "integer"},
+		"email": {"type": "string"}
+	}
+}`
+
+func TestRequestBuilder_ExpectSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/users/1" {
+			_, _ = w.Write([]byte(`{"id": 1, "email": "john@example.com"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 2, "name": "Jane"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var user map[string]interface{}
+	if err := client.GET("/users/1").ExpectSchema([]byte(userSchema)).Do(&user); err != nil {
+		t.Fatalf("Expected valid response to pass, got %v", err)
+	}
+	if user["email"] != "john@example.com" {
+		t.Errorf("Expected response to be decoded, got %v", user)
+	}
+
+	user = nil
+	err := client.GET("/users/2").ExpectSchema([]byte(userSchema)).Do(&user)
+	if err == nil {
+		t.Fatal("Expected schema validation error for missing required field")
+	}
+	if !strings.Contains(err.Error(), "email") {
+		t.Errorf("Expected error to name the missing field, got %v", err)
+	}
+	if user != nil {
+		t.Errorf("Expected result to be left untouched, got %v", user)
+	}
+}
+
+func TestRequestBuilder_ExpectSchemaInvalid(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	})
+
+	err := client.GET("/users/1").ExpectSchema([]byte(`{"type": 42}`)).Do(nil)
+	if err == nil || !strings.Contains(err.Error(), "schema") {
+		t.Errorf("Expected schema compile error, got %v", err)
+	}
+}
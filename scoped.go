@@ -0,0 +1,33 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// WithScopedHeaders returns a copy of ctx carrying headers that are sent with
+// every request built with that context (see RequestBuilder.WithContext),
+// such as a tenant or trace header shared by a chain of sub-requests.
+// Headers from an enclosing scope are kept unless overridden. Headers set on
+// the builder take precedence, and request middleware runs afterwards.
+//
+// Example usage:
+//
+//	ctx = httpclient.WithScopedHeaders(ctx, map[string]string{"X-Tenant": tenant})
+//	err := client.GET("/orders").WithContext(ctx).Do(&orders)
+func WithScopedHeaders(ctx context.Context, headers map[string]string) context.Context {
+	scoped := ScopedHeadersFromContext(ctx).Clone()
+	if scoped == nil {
+		scoped = make(http.Header, len(headers))
+	}
+	for k, v := range headers {
+		scoped.Set(k, v)
+	}
+	return context.WithValue(ctx, scopedHeadersKey, scoped)
+}
+
+// ScopedHeadersFromContext returns the headers attached by WithScopedHeaders
+func ScopedHeadersFromContext(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(scopedHeadersKey).(http.Header)
+	return headers
+}
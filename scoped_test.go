@@ -0,0 +1,54 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithScopedHeaders(t *testing.T) {
+	var received []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Clone())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	ctx := WithScopedHeaders(context.Background(), map[string]string{"X-Tenant": "acme", "X-Trace": "outer"})
+	ctx = WithScopedHeaders(ctx, map[string]string{"X-Trace": "inner"})
+
+	if err := client.GET("/orders").WithContext(ctx).Do(nil); err != nil {
+		t.Fatalf("First request failed: %v", err)
+	}
+	if err := client.POST("/orders/1/items").WithContext(ctx).WithHeader("X-Trace", "explicit").Do(nil); err != nil {
+		t.Fatalf("Second request failed: %v", err)
+	}
+	if err := client.GET("/health").Do(nil); err != nil {
+		t.Fatalf("Unscoped request failed: %v", err)
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(received))
+	}
+	for i, h := range received[:2] {
+		if got := h.Get("X-Tenant"); got != "acme" {
+			t.Errorf("Request %d: expected X-Tenant 'acme', got '%s'", i, got)
+		}
+	}
+	if got := received[0].Get("X-Trace"); got != "inner" {
+		t.Errorf("Expected inner scope to override X-Trace, got '%s'", got)
+	}
+	if got := received[1].Get("X-Trace"); got != "explicit" {
+		t.Errorf("Expected builder header to win, got '%s'", got)
+	}
+	if got := received[2].Get("X-Tenant"); got != "" {
+		t.Errorf("Expected no scoped header without the context, got '%s'", got)
+	}
+}
@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// SlogOptions configures SlogMiddleware
+type SlogOptions struct {
+	// RedactHeaders lists headers whose values are logged as "***", as in
+	// DebugOptions (default: DefaultRedactHeaders). Set an empty, non-nil slice
+	// to log all values.
+	RedactHeaders []string
+}
+
+// SlogMiddleware returns a middleware that logs each request to logger at debug
+// level, with the method, path and headers as structured attributes. Header
+// values are redacted as configured in opts, which may be nil.
+// Pair it with SlogResponseMiddleware to log outcomes.
+func SlogMiddleware(logger *slog.Logger, opts *SlogOptions) Middleware {
+	redact := DefaultRedactHeaders
+	if opts != nil && opts.RedactHeaders != nil {
+		redact = opts.RedactHeaders
+	}
+
+	return func(req *http.Request) error {
+		logger.LogAttrs(req.Context(), slog.LevelDebug, "http request",
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.Any("headers", redactHeaders(req.Header, redact)),
+		)
+		return nil
+	}
+}
+
+// SlogResponseMiddleware returns a response middleware that logs each response
+// to logger with the method, path, status and duration of the call as structured
+// attributes. 4xx responses are logged as warnings and 5xx responses as errors.
+//
+// Example usage:
+//
+//	client := httpclient.NewClient(config,
+//	    httpclient.WithMiddleware(httpclient.SlogMiddleware(logger, nil)),
+//	    httpclient.WithResponseMiddleware(httpclient.SlogResponseMiddleware(logger)))
+func SlogResponseMiddleware(logger *slog.Logger) ResponseMiddleware {
+	return func(resp *http.Response) error {
+		ctx := context.Background()
+		attrs := []slog.Attr{slog.Int("status", resp.StatusCode)}
+		if req := resp.Request; req != nil {
+			ctx = req.Context()
+			attrs = append(attrs,
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+			)
+			if start, ok := RequestStartFromContext(ctx); ok {
+				attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+			}
+			if id, ok := RequestIDFromContext(ctx); ok {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case resp.StatusCode >= 500:
+			level = slog.LevelError
+		case resp.StatusCode >= 400:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx, level, "http response", attrs...)
+		return nil
+	}
+}
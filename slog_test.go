@@ -0,0 +1,152 @@
+package httpclient
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHandler is a slog.Handler that captures records
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+// attrs returns the attributes of the record with the given message
+func (h *recordingHandler) attrs(msg string) (map[string]slog.Value, slog.Level, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range h.records {
+		if r.Message != msg {
+			continue
+		}
+		attrs := make(map[string]slog.Value)
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		return attrs, r.Level, true
+	}
+	return nil, 0, false
+}
+
+func TestSlogMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	handler := &recordingHandler{}
+	logger := slog.New(handler)
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(SlogMiddleware(logger, nil)),
+		WithResponseMiddleware(SlogResponseMiddleware(logger)))
+
+	_, err := client.GET("/users/1").
+		WithHeader("Authorization", "Bearer secret").
+		WithHeader("Accept", "application/json").
+		DoStatus()
+	if err == nil {
+		t.Fatal("Expected error for 404 response")
+	}
+
+	attrs, level, ok := handler.attrs("http request")
+	if !ok {
+		t.Fatal("Expected request to be logged")
+	}
+	if level != slog.LevelDebug {
+		t.Errorf("Expected request level DEBUG, got %v", level)
+	}
+	if got := attrs["method"].String(); got != http.MethodGet {
+		t.Errorf("Expected method GET, got %s", got)
+	}
+	if got := attrs["path"].String(); got != "/users/1" {
+		t.Errorf("Expected path /users/1, got %s", got)
+	}
+	headers, _ := attrs["headers"].Any().(http.Header)
+	if got := headers.Get("Authorization"); got != "***" {
+		t.Errorf("Expected Authorization to be redacted, got %q", got)
+	}
+	if got := headers.Get("Accept"); got != "application/json" {
+		t.Errorf("Expected Accept application/json, got %q", got)
+	}
+
+	attrs, level, ok = handler.attrs("http response")
+	if !ok {
+		t.Fatal("Expected response to be logged")
+	}
+	if level != slog.LevelWarn {
+		t.Errorf("Expected response level WARN, got %v", level)
+	}
+	if got := attrs["method"].String(); got != http.MethodGet {
+		t.Errorf("Expected method GET, got %s", got)
+	}
+	if got := attrs["path"].String(); got != "/users/1" {
+		t.Errorf("Expected path /users/1, got %s", got)
+	}
+	if got := attrs["status"].Int64(); got != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", got)
+	}
+	duration, ok := attrs["duration"]
+	if !ok {
+		t.Fatal("Expected duration attribute")
+	}
+	if got := duration.Duration(); got < 10*time.Millisecond {
+		t.Errorf("Expected duration of at least 10ms, got %v", got)
+	}
+}
+
+func TestSlogMiddleware_RedactHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	handler := &recordingHandler{}
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithMiddleware(SlogMiddleware(slog.New(handler), &SlogOptions{
+		RedactHeaders: []string{"X-Session"},
+	})))
+
+	_, err := client.GET("/users/1").
+		WithHeader("X-Session", "secret").
+		WithHeader("Authorization", "Bearer token").
+		DoStatus()
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	attrs, _, ok := handler.attrs("http request")
+	if !ok {
+		t.Fatal("Expected request to be logged")
+	}
+	headers, _ := attrs["headers"].Any().(http.Header)
+	if got := headers.Get("X-Session"); got != "***" {
+		t.Errorf("Expected X-Session to be redacted, got %q", got)
+	}
+	if got := headers.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Expected Authorization to be logged, got %q", got)
+	}
+}
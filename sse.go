@@ -0,0 +1,223 @@
+package httpclient
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSEEvent is a server-sent event
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	// Retry is the reconnection delay requested by the server, if any
+	Retry time.Duration
+}
+
+// SSEOptions configures DoSSE
+type SSEOptions struct {
+	// Reconnect re-establishes the stream when it ends, fails with a transport
+	// error or gets a 5xx or 429 response, sending the last received event ID
+	// in the Last-Event-ID header. Other error responses are returned, and a
+	// 204 No Content response ends the stream for good.
+	Reconnect bool
+	// MaxReconnects limits consecutive reconnects without receiving an event (0 means unlimited)
+	MaxReconnects int
+	// WaitTime and MaxWaitTime bound the exponential backoff between reconnects
+	// (defaults: DefaultRetryWaitTime and DefaultRetryMaxWaitTime)
+	WaitTime    time.Duration
+	MaxWaitTime time.Duration
+}
+
+// DoSSE executes the request and calls handler for each server-sent event
+// until the stream ends, the handler returns an error, or the context is done.
+// With Reconnect set in opts, the stream is resumed after it ends or fails,
+// backing off exponentially and stopping when the context is cancelled.
+// Response middleware sees the live stream, as with DoStream.
+//
+// Example usage:
+//
+//	err := client.GET("/events").DoSSE(func(ev *httpclient.SSEEvent) error {
+//	    fmt.Println(ev.Event, ev.Data)
+//	    return nil
+//	}, &httpclient.SSEOptions{Reconnect: true})
+func (b *RequestBuilder) DoSSE(handler func(*SSEEvent) error, opts *SSEOptions) error {
+	if b.err != nil {
+		return b.err
+	}
+	if err := b.consume(); err != nil {
+		return err
+	}
+	if opts == nil {
+		opts = &SSEOptions{}
+	}
+
+	waitTime, maxWaitTime := opts.WaitTime, opts.MaxWaitTime
+	if waitTime == 0 {
+		waitTime = DefaultRetryWaitTime
+	}
+	if maxWaitTime == 0 {
+		maxWaitTime = DefaultRetryMaxWaitTime
+	}
+
+	b.headers.Set("Accept", "text/event-stream")
+	b.streaming = true
+
+	var lastID string
+	var serverRetry time.Duration
+	failures := 0
+	for {
+		if lastID != "" {
+			b.headers.Set("Last-Event-ID", lastID)
+		}
+
+		received, err := b.streamSSE(func(ev *SSEEvent) error {
+			if ev.ID != "" {
+				lastID = ev.ID
+			}
+			if ev.Retry > 0 {
+				serverRetry = ev.Retry
+			}
+			return handler(ev)
+		})
+		var handlerErr *sseHandlerError
+		if errors.As(err, &handlerErr) {
+			return handlerErr.err
+		}
+		if errors.Is(err, errSSENoContent) {
+			return nil
+		}
+		if !opts.Reconnect || !shouldReconnectSSE(err) {
+			return err
+		}
+		if ctxErr := b.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if received {
+			failures = 0
+		}
+		if opts.MaxReconnects > 0 && failures >= opts.MaxReconnects {
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("event stream failed after %d reconnects: %w", failures, err)
+		}
+
+		wait := calculateBackoff(failures, waitTime, maxWaitTime, b.client.retryRand)
+		if serverRetry > 0 {
+			wait = serverRetry
+		}
+		failures++
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-b.ctx.Done():
+			timer.Stop()
+			return b.ctx.Err()
+		}
+	}
+}
+
+// sseHandlerError marks an error returned by the DoSSE handler, which is never retried
+type sseHandlerError struct {
+	err error
+}
+
+func (e *sseHandlerError) Error() string { return e.err.Error() }
+
+// errSSENoContent reports a 204 response, by which the server asks the client to stop reconnecting
+var errSSENoContent = errors.New("event stream closed with 204 No Content")
+
+// shouldReconnectSSE reports whether a stream ending with err is worth resuming:
+// after a clean end, a transport error, or a 5xx or 429 response
+func shouldReconnectSSE(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsServerError() || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// streamSSE opens the stream once and dispatches its events, reporting whether
+// any event was received. A clean end of stream returns a nil error.
+func (b *RequestBuilder) streamSSE(handler func(*SSEEvent) error) (bool, error) {
+	resp, err := b.execute()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, b.client.handleErrorResponse(resp)
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return false, errSSENoContent
+	}
+
+	received := false
+	err = parseSSE(resp.Body, func(ev *SSEEvent) error {
+		received = true
+		if err := handler(ev); err != nil {
+			return &sseHandlerError{err: err}
+		}
+		return nil
+	})
+	return received, err
+}
+
+// parseSSE reads an event stream, calling dispatch for each complete event
+func parseSSE(r io.Reader, dispatch func(*SSEEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	event := &SSEEvent{}
+	var data []string
+	hasData := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// A blank line dispatches the pending event
+		if line == "" {
+			if hasData {
+				event.Data = strings.Join(data, "\n")
+				if err := dispatch(event); err != nil {
+					return err
+				}
+			}
+			event, data, hasData = &SSEEvent{}, nil, false
+			continue
+		}
+
+		// Comment line
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				event.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return scanner.Err()
+}
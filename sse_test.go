@@ -0,0 +1,161 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestBuilder_DoSSE(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Expected Accept text/event-stream, got '%s'", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": comment\nid: 1\nevent: greeting\ndata: hello\ndata: world\n\nid: 2\ndata: bye\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var events []SSEEvent
+	err := client.GET("/events").DoSSE(func(ev *SSEEvent) error {
+		events = append(events, *ev)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("DoSSE failed: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].ID != "1" || events[0].Event != "greeting" || events[0].Data != "hello\nworld" {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[1].ID != "2" || events[1].Data != "bye" {
+		t.Errorf("Unexpected second event: %+v", events[1])
+	}
+}
+
+func TestRequestBuilder_DoSSE_Reconnect(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		if atomic.AddInt32(&connections, 1) == 1 {
+			// Drop the stream after two events
+			_, _ = fmt.Fprint(w, "id: 1\ndata: a\n\nid: 2\ndata: b\n\n")
+			return
+		}
+		if got := r.Header.Get("Last-Event-ID"); got != "2" {
+			t.Errorf("Expected Last-Event-ID '2', got '%s'", got)
+		}
+		_, _ = fmt.Fprint(w, "id: 3\ndata: c\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	errDone := errors.New("done")
+	var data []string
+	err := client.GET("/events").DoSSE(func(ev *SSEEvent) error {
+		data = append(data, ev.Data)
+		if ev.ID == "3" {
+			return errDone
+		}
+		return nil
+	}, &SSEOptions{Reconnect: true, WaitTime: time.Millisecond, MaxWaitTime: 5 * time.Millisecond})
+
+	if !errors.Is(err, errDone) {
+		t.Fatalf("Expected handler error, got %v", err)
+	}
+	if fmt.Sprint(data) != "[a b c]" {
+		t.Errorf("Expected events [a b c], got %v", data)
+	}
+	if got := atomic.LoadInt32(&connections); got != 2 {
+		t.Errorf("Expected 2 connections, got %d", got)
+	}
+}
+
+func TestRequestBuilder_DoSSE_ReconnectStopsOnClientError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"unauthorized", http.StatusUnauthorized, true},
+		{"not found", http.StatusNotFound, true},
+		{"no content", http.StatusNoContent, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var connections int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt32(&connections, 1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(&Config{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			})
+
+			err := client.GET("/events").DoSSE(func(*SSEEvent) error {
+				return nil
+			}, &SSEOptions{Reconnect: true, WaitTime: time.Millisecond, MaxWaitTime: 5 * time.Millisecond})
+
+			var apiErr *APIError
+			if tt.wantErr && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.status) {
+				t.Errorf("Expected APIError with status %d, got %v", tt.status, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if got := atomic.LoadInt32(&connections); got != 1 {
+				t.Errorf("Expected 1 connection, got %d", got)
+			}
+		})
+	}
+}
+
+func TestRequestBuilder_DoSSE_ReconnectsOnServerError(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&connections, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "data: ok\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	errDone := errors.New("done")
+	err := client.GET("/events").DoSSE(func(*SSEEvent) error {
+		return errDone
+	}, &SSEOptions{Reconnect: true, WaitTime: time.Millisecond, MaxWaitTime: 5 * time.Millisecond})
+
+	if !errors.Is(err, errDone) {
+		t.Fatalf("Expected handler error, got %v", err)
+	}
+	if got := atomic.LoadInt32(&connections); got != 2 {
+		t.Errorf("Expected 2 connections, got %d", got)
+	}
+}
@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Connection phases reported by WithTrace
+const (
+	// TracePhaseDNS is the DNS lookup of the host
+	TracePhaseDNS = "dns"
+	// TracePhaseConnect is the establishment of the TCP connection
+	TracePhaseConnect = "connect"
+	// TracePhaseTLS is the TLS handshake
+	TracePhaseTLS = "tls"
+	// TracePhaseFirstByte is the time from requesting a connection to the first
+	// response byte, including the phases above when a new connection is dialed
+	TracePhaseFirstByte = "first_byte"
+)
+
+// TraceMiddleware creates a middleware that reports the duration of each
+// connection phase of a request to fn, once per attempt. Phases that do not
+// occur, such as DNS and connect on a reused connection, are not reported.
+// fn may be called from the transport's goroutines.
+func TraceMiddleware(fn func(phase string, d time.Duration)) Middleware {
+	return func(req *http.Request) error {
+		var (
+			mu                        sync.Mutex
+			start, dnsStart, tlsStart time.Time
+			connectStart              = make(map[string]time.Time)
+		)
+		// since reads a start time set from another of the transport's goroutines
+		since := func(t *time.Time) time.Duration {
+			mu.Lock()
+			defer mu.Unlock()
+			return time.Since(*t)
+		}
+
+		ctx := httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+			GetConn: func(string) {
+				mu.Lock()
+				start = time.Now()
+				mu.Unlock()
+			},
+			DNSStart: func(httptrace.DNSStartInfo) {
+				mu.Lock()
+				dnsStart = time.Now()
+				mu.Unlock()
+			},
+			DNSDone: func(httptrace.DNSDoneInfo) {
+				fn(TracePhaseDNS, since(&dnsStart))
+			},
+			ConnectStart: func(_, addr string) {
+				mu.Lock()
+				connectStart[addr] = time.Now()
+				mu.Unlock()
+			},
+			ConnectDone: func(_, addr string, err error) {
+				mu.Lock()
+				t, ok := connectStart[addr]
+				delete(connectStart, addr)
+				mu.Unlock()
+				if ok && err == nil {
+					fn(TracePhaseConnect, time.Since(t))
+				}
+			},
+			TLSHandshakeStart: func() {
+				mu.Lock()
+				tlsStart = time.Now()
+				mu.Unlock()
+			},
+			TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+				if err == nil {
+					fn(TracePhaseTLS, since(&tlsStart))
+				}
+			},
+			GotFirstResponseByte: func() {
+				fn(TracePhaseFirstByte, since(&start))
+			},
+		})
+		*req = *req.WithContext(ctx)
+		return nil
+	}
+}
+
+// WithTrace reports the duration of the DNS lookup, connection, TLS handshake
+// and time to first byte of every request attempt to fn, for a latency breakdown
+//
+// Example usage:
+//
+//	client := httpclient.NewClient(config,
+//	    httpclient.WithTrace(func(phase string, d time.Duration) {
+//	        latency.WithLabelValues(phase).Observe(d.Seconds())
+//	    }))
+func WithTrace(fn func(phase string, d time.Duration)) Option {
+	return func(c *HTTPClient) {
+		c.middleware = append(c.middleware, TraceMiddleware(fn))
+	}
+}
@@ -0,0 +1,43 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithTrace(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	phases := make(map[string]time.Duration)
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithHTTPClient(server.Client()), WithTrace(func(phase string, d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		phases[phase] = d
+	}))
+
+	if err := client.GET("/ping").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, phase := range []string{TracePhaseConnect, TracePhaseTLS, TracePhaseFirstByte} {
+		if _, ok := phases[phase]; !ok {
+			t.Errorf("Expected phase %s to be reported, got %v", phase, phases)
+		}
+	}
+	if d := phases[TracePhaseFirstByte]; d < 10*time.Millisecond {
+		t.Errorf("Expected time to first byte of at least 10ms, got %v", d)
+	}
+}
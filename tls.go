@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// WithTLSConfig sets the TLS configuration of the default transport, keeping
+// its connection pool settings. The config is copied, so later changes to it
+// have no effect. WithRootCAs, WithClientCert and WithInsecureSkipVerify add to
+// it regardless of the order of the options. This has no effect with
+// WithHTTPClient.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *HTTPClient) {
+		if config == nil {
+			return
+		}
+		c.tlsBase = config.Clone()
+	}
+}
+
+// WithRootCAs trusts the PEM-encoded CA certificates in pem, such as a private
+// CA bundle, instead of the system roots. If no certificate can be parsed,
+// every request fails with an error. This has no effect with WithHTTPClient.
+func WithRootCAs(pem []byte) Option {
+	return func(c *HTTPClient) {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			c.configErr = errors.New("no valid certificates found in root CA PEM")
+			return
+		}
+		c.tlsOptions = append(c.tlsOptions, func(config *tls.Config) {
+			config.RootCAs = pool
+		})
+	}
+}
+
+// WithClientCert presents cert to servers that request a client certificate,
+// for mutual TLS. It can be combined with WithRootCAs and applied more than
+// once to offer several certificates. This has no effect with WithHTTPClient.
+func WithClientCert(cert tls.Certificate) Option {
+	return func(c *HTTPClient) {
+		c.tlsOptions = append(c.tlsOptions, func(config *tls.Config) {
+			config.Certificates = append(config.Certificates, cert)
+		})
+	}
+}
+
+// WithInsecureSkipVerify disables verification of server certificates and host
+// names, for local development against self-signed certificates.
+//
+// WARNING: this makes connections vulnerable to man-in-the-middle attacks.
+// Never use it in production; trust a private CA with WithRootCAs instead.
+// Other TLS settings are kept. This has no effect with WithHTTPClient.
+func WithInsecureSkipVerify() Option {
+	return func(c *HTTPClient) {
+		c.tlsOptions = append(c.tlsOptions, func(config *tls.Config) {
+			config.InsecureSkipVerify = true
+		})
+	}
+}
+
+// applyTLS builds the TLS configuration of the default transport from the TLS
+// options, once all options have been applied
+func (c *HTTPClient) applyTLS() {
+	if c.transport == nil || (c.tlsBase == nil && len(c.tlsOptions) == 0) {
+		return
+	}
+	config := c.tlsBase
+	if config == nil {
+		config = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	for _, apply := range c.tlsOptions {
+		apply(config)
+	}
+	c.transport.TLSClientConfig = config
+}
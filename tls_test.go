@@ -0,0 +1,318 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_WithRootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		BaseURL:         server.URL,
+		Timeout:         5 * time.Second,
+		IdleConnTimeout: 30 * time.Second,
+	}
+
+	// Without the server's CA the handshake fails
+	if err := NewClient(config).GET("/").Do(nil); err == nil {
+		t.Fatal("Expected certificate verification error without root CAs")
+	}
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	client := NewClient(config, WithRootCAs(caPEM))
+	if err := client.GET("/").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	transport := client.(*HTTPClient).transport
+	if transport.IdleConnTimeout != 30*time.Second || transport.MaxIdleConnsPerHost != 100 {
+		t.Errorf("Expected pool settings to be kept, got idle timeout %v and %d conns per host",
+			transport.IdleConnTimeout, transport.MaxIdleConnsPerHost)
+	}
+}
+
+func TestClient_DefaultHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	client := NewClient(&Config{
+		BaseURL:     server.URL,
+		Timeout:     5 * time.Second,
+		DialTimeout: time.Second,
+	}, WithRootCAs(caPEM))
+
+	resp, err := client.GET("/").DoResponse(nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if got := resp.Header.Get("X-Proto"); got != "HTTP/2.0" {
+		t.Errorf("Expected HTTP/2.0 by default, got %s", got)
+	}
+}
+
+func TestClient_WithHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	config := &Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}
+
+	tests := []struct {
+		enabled bool
+		proto   string
+	}{
+		{enabled: true, proto: "HTTP/2.0"},
+		{enabled: false, proto: "HTTP/1.1"},
+	}
+	for _, tt := range tests {
+		client := NewClient(config, WithRootCAs(caPEM), WithHTTP2(tt.enabled))
+
+		protocols := client.(*HTTPClient).transport.Protocols
+		if protocols == nil || protocols.HTTP2() != tt.enabled || protocols.HTTP1() == tt.enabled {
+			t.Errorf("WithHTTP2(%v): unexpected transport protocols %v", tt.enabled, protocols)
+		}
+
+		resp, err := client.GET("/").DoResponse(nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if got := resp.Header.Get("X-Proto"); got != tt.proto {
+			t.Errorf("WithHTTP2(%v): expected %s, got %s", tt.enabled, tt.proto, got)
+		}
+	}
+}
+
+func TestClient_WithHTTP2_Cleartext(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	config := &Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}
+
+	tests := []struct {
+		enabled bool
+		proto   string
+	}{
+		{enabled: true, proto: "HTTP/2.0"},
+		{enabled: false, proto: "HTTP/1.1"},
+	}
+	for _, tt := range tests {
+		client := NewClient(config, WithHTTP2(tt.enabled))
+
+		resp, err := client.GET("/").DoResponse(nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if got := resp.Header.Get("X-Proto"); got != tt.proto {
+			t.Errorf("WithHTTP2(%v) over cleartext: expected %s, got %s", tt.enabled, tt.proto, got)
+		}
+	}
+}
+
+func TestClient_WithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithTLSConfig(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}))
+
+	if err := client.GET("/").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+func TestClient_WithRootCAsInvalid(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	}, WithRootCAs([]byte("not a certificate")))
+
+	if err := client.GET("/").Do(nil); err == nil {
+		t.Error("Expected error for invalid root CA PEM")
+	}
+}
+
+func TestClient_WithClientCert(t *testing.T) {
+	caCert, caKey := newTestCA(t)
+	clientCert := newTestClientCert(t, caCert, caKey)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			t.Error("Expected a verified client certificate")
+		} else if cn := r.TLS.PeerCertificates[0].Subject.CommonName; cn != "test-client" {
+			t.Errorf("Expected client CN 'test-client', got '%s'", cn)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	config := &Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	if err := NewClient(config, WithRootCAs(serverCA)).GET("/").Do(nil); err == nil {
+		t.Fatal("Expected handshake to fail without a client certificate")
+	}
+
+	client := NewClient(config, WithRootCAs(serverCA), WithClientCert(clientCert))
+	if err := client.GET("/").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+}
+
+// newTestCA creates a self-signed CA certificate
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse CA certificate: %v", err)
+	}
+	return cert, key
+}
+
+// newTestClientCert issues a client certificate signed by the CA
+func newTestClientCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate client key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create client certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestClient_WithInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12, ServerName: "example.com"}), WithInsecureSkipVerify())
+
+	if err := client.GET("/").Do(nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	tlsConfig := client.(*HTTPClient).transport.TLSClientConfig
+	if !tlsConfig.InsecureSkipVerify || tlsConfig.ServerName != "example.com" {
+		t.Errorf("Expected skip-verify to be added to the existing TLS config, got %+v", tlsConfig)
+	}
+}
+
+func TestClient_TLSOptionOrder(t *testing.T) {
+	caCert, caKey := newTestCA(t)
+	clientCert := newTestClientCert(t, caCert, caKey)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+
+	client := NewClient(&Config{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+	},
+		WithInsecureSkipVerify(),
+		WithClientCert(clientCert),
+		WithRootCAs(caPEM),
+		WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13, ServerName: "example.com"}),
+	)
+
+	tlsConfig := client.(*HTTPClient).transport.TLSClientConfig
+	if tlsConfig.MinVersion != tls.VersionTLS13 || tlsConfig.ServerName != "example.com" {
+		t.Errorf("Expected settings from WithTLSConfig, got %+v", tlsConfig)
+	}
+	if !tlsConfig.InsecureSkipVerify {
+		t.Error("Expected WithInsecureSkipVerify before WithTLSConfig to be kept")
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Errorf("Expected 1 client certificate, got %d", len(tlsConfig.Certificates))
+	}
+	if tlsConfig.RootCAs == nil {
+		t.Error("Expected WithRootCAs before WithTLSConfig to be kept")
+	}
+}
@@ -0,0 +1,126 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Upgrade performs the request as a protocol upgrade handshake (e.g. WebSocket)
+// and returns the raw connection once the server answers 101 Switching Protocols.
+// Headers and request middleware are applied as for any other request; the caller
+// sets the Upgrade header and any protocol-specific headers. Retries and response
+// middleware are not applied. The caller must close the returned connection.
+func (b *RequestBuilder) Upgrade() (net.Conn, *http.Response, error) {
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+	if err := b.consume(); err != nil {
+		return nil, nil, err
+	}
+
+	req, err := b.buildRequest(b.ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Header.Get("Connection") == "" {
+		req.Header.Set("Connection", "Upgrade")
+	}
+
+	ctx := req.Context()
+	conn, err := b.client.dialUpgrade(ctx, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to dial for upgrade: %w", err)
+	}
+
+	// Bound the handshake by the context deadline
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("failed to write upgrade request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("failed to read upgrade response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer func() { _ = conn.Close() }()
+		return nil, resp, b.client.handleErrorResponse(resp)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return &upgradedConn{Conn: conn, r: br}, resp, nil
+}
+
+// dialUpgrade opens a connection to the request's host, using the dialer and TLS
+// settings of the client's transport when available
+func (c *HTTPClient) dialUpgrade(ctx context.Context, req *http.Request) (net.Conn, error) {
+	var transport *http.Transport
+	if hc, ok := c.httpClient.(*http.Client); ok {
+		transport, _ = hc.Transport.(*http.Transport)
+	}
+
+	host := req.URL.Hostname()
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" || req.URL.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	addr := net.JoinHostPort(host, port)
+
+	dial := (&net.Dialer{}).DialContext
+	if transport != nil && transport.DialContext != nil {
+		dial = transport.DialContext
+	}
+
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL.Scheme != "https" && req.URL.Scheme != "wss" {
+		return conn, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if transport != nil && transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+	// The upgrade handshake is HTTP/1.1 only
+	tlsConfig.NextProtos = []string{"http/1.1"}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// upgradedConn is a connection whose reads first drain data buffered while
+// reading the upgrade response
+type upgradedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read reads from the buffered reader, then from the connection
+func (c *upgradedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,36 @@
+package httpclient
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLCodec encodes and decodes application/yaml bodies with gopkg.in/yaml.v3
+var YAMLCodec Codec = yamlCodec{}
+
+// yamlCodec implements Codec with gopkg.in/yaml.v3
+type yamlCodec struct{}
+
+func (yamlCodec) ContentType() string { return "application/yaml" }
+
+func (yamlCodec) Encode(v interface{}) ([]byte, error) { return yaml.Marshal(v) }
+
+func (yamlCodec) Decode(r io.Reader, v interface{}) error { return yaml.NewDecoder(r).Decode(v) }
+
+// WithYAML serializes the given object as YAML and sets it as the request body
+// Automatically sets Content-Type: application/yaml
+// A YAML codec registered with WithCodec is used instead of gopkg.in/yaml.v3.
+func (b *RequestBuilder) WithYAML(v interface{}) *RequestBuilder {
+	codec, ok := b.client.codecFor(YAMLCodec.ContentType())
+	if !ok {
+		codec = YAMLCodec
+	}
+	return b.withCodecBody(codec, "YAML", v)
+}
+
+// DoYAML executes the HTTP request like Do but decodes the response as YAML
+func (b *RequestBuilder) DoYAML(result interface{}) error {
+	_, _, err := b.do(result, YAMLCodec, false)
+	return err
+}
@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_YAML(t *testing.T) {
+	type Service struct {
+		Name     string   `yaml:"name"`
+		Replicas int      `yaml:"replicas"`
+		Ports    []int    `yaml:"ports"`
+		Tags     []string `yaml:"tags,omitempty"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/yaml" {
+			t.Errorf("Expected Content-Type application/yaml, got %s", ct)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		expected := "name: api\nreplicas: 2\nports:\n    - 80\n    - 443\n"
+		if string(body) != expected {
+			t.Errorf("Expected YAML body %q, got %q", expected, string(body))
+		}
+
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte("name: api\nreplicas: 3\nports: [80, 443]\ntags:\n  - public\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	var result Service
+	err := client.PUT("/config/services/api").
+		WithYAML(Service{Name: "api", Replicas: 2, Ports: []int{80, 443}}).
+		DoYAML(&result)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if result.Name != "api" || result.Replicas != 3 || len(result.Ports) != 2 || len(result.Tags) != 1 || result.Tags[0] != "public" {
+		t.Errorf("Response not decoded correctly, got: %+v", result)
+	}
+}